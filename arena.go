@@ -0,0 +1,514 @@
+package trie
+
+// noNode is the index of a missing node in an arena
+const noNode int32 = -1
+
+// arenaSlotBytes is the size of a node in the slices of an arena, not counting spare capacity
+const arenaSlotBytes = 4 + 1 + 4 + 4 + 4 + 16 + 4
+
+const (
+	arenaTerm uint8 = 1 << iota
+	arenaRoot
+)
+
+// arena stores the nodes of a trie in parallel slices indexed by node, so a trie of millions of
+// nodes is a handful of allocations with a single slice of pointers for the data. The children of a
+// node are linked from its first child through the next sibling in ascending order of their runes.
+// Removed nodes are linked into a free list through their next sibling and reused
+type arena struct {
+	values  []rune
+	flags   []uint8
+	parents []int32
+	firsts  []int32
+	nexts   []int32
+	data    []interface{}
+	free    int32
+
+	// gens counts how many times every node was freed, so a handle can tell that its node was
+	// removed even once the node is reused
+	gens []uint32
+}
+
+// arenaNode is a node of an arena as the package works with it. Nodes are small values, so handles
+// to the same node are equal. Nodes given out of the package are wrapped in an arenaHandle
+type arenaNode struct {
+	a *arena
+	i int32
+}
+
+// arenaHandle is a node of an arena given out of the package. It holds the generation of its node
+// along with the rune and data the node had when it was given out, so once its node is removed it is
+// a detached node with that rune and data, like the removed nodes of a trie without an arena, and
+// the node can still be reused
+type arenaHandle struct {
+	arenaNode
+	gen   uint32
+	value rune
+	data  interface{}
+}
+
+// WithArena stores the nodes of the trie and of its suffix index in an arena instead of a separate
+// allocation per node and map of children. Removed nodes are reused. A node handed out, such as the
+// nodes returned by Find, Add and Update or visited by Walk, is detached once its word is removed,
+// keeping the rune and data it had when it was handed out but no longer its path, parent or children
+func WithArena() Option {
+	return func(t *Trie) {
+		t.Root = newArena().alloc(0, noNode, arenaRoot).handle()
+	}
+}
+
+// newArena creates an empty arena
+func newArena() *arena {
+	return &arena{free: noNode}
+}
+
+// alloc gives a node for the rune under the parent, reusing a free slot first
+func (a *arena) alloc(r rune, parent int32, flags uint8) arenaNode {
+	i := a.free
+	if i != noNode {
+		a.free = a.nexts[i]
+		a.values[i], a.flags[i], a.parents[i], a.firsts[i], a.nexts[i] = r, flags, parent, noNode, noNode
+	} else {
+		i = int32(len(a.values))
+		a.values = append(a.values, r)
+		a.flags = append(a.flags, flags)
+		a.parents = append(a.parents, parent)
+		a.firsts = append(a.firsts, noNode)
+		a.nexts = append(a.nexts, noNode)
+		a.data = append(a.data, nil)
+		a.gens = append(a.gens, 0)
+	}
+
+	return arenaNode{a: a, i: i}
+}
+
+// release adds a node which was removed from the trie and every node below it to the free list.
+// Handles to them are detached
+func (a *arena) release(i int32) {
+	for c := a.firsts[i]; c != noNode; {
+		next := a.nexts[c]
+		a.release(c)
+		c = next
+	}
+
+	a.flags[i], a.parents[i], a.firsts[i] = 0, noNode, noNode
+	a.data[i] = nil
+	a.gens[i]++
+	a.nexts[i] = a.free
+	a.free = i
+}
+
+// child gives the child of the node for the rune, or noNode, along with the previous sibling where
+// a child for the rune belongs, or noNode if it belongs first
+func (a *arena) child(i int32, r rune) (int32, int32) {
+	prev := noNode
+	for c := a.firsts[i]; c != noNode; c = a.nexts[c] {
+		if a.values[c] == r {
+			return c, prev
+		}
+		if a.values[c] > r {
+			break
+		}
+		prev = c
+	}
+	return noNode, prev
+}
+
+// descend follows the runes from the node and gives the node where the runes which matched end
+// along with how many matched
+func (a *arena) descend(i int32, runes []rune) (int32, int) {
+	for pos, r := range runes {
+		c, _ := a.child(i, r)
+		if c == noNode {
+			return i, pos
+		}
+		i = c
+	}
+	return i, len(runes)
+}
+
+// find gives the terminating node where the runes from pos end like Trie.findAtNode, without a
+// handle for every node on the way
+func (a *arena) find(i int32, runes []rune, pos int) (Node, error) {
+	i, matched := a.descend(i, runes[pos:])
+	if matched < len(runes)-pos {
+		end := pos + matched
+		return nil, &NotFoundError{Key: string(runes), Prefix: string(runes[:end]), Pos: end}
+	}
+	if a.flags[i]&arenaTerm == 0 {
+		return nil, &NotFoundError{Key: string(runes), Prefix: string(runes), Pos: len(runes)}
+	}
+	return arenaNode{a: a, i: i}, nil
+}
+
+// addArenaPath gets or adds the nodes along the runes from the node like Trie.addPath, without a
+// handle for every node on the way. The nodes added are a single branch, so removing its first node
+// reverts them in a batch
+func (t *Trie) addArenaPath(an arenaNode, runes []rune) Node {
+	a := an.a
+	i, matched := a.descend(an.i, runes)
+	if matched == len(runes) {
+		return arenaNode{a: a, i: i}
+	}
+
+	if t.undo != nil {
+		parent, r := arenaNode{a: a, i: i}, runes[matched]
+		t.undo.record(func() { parent.removeChild(r) })
+	}
+
+	for _, r := range runes[matched:] {
+		_, prev := a.child(i, r)
+		c := a.alloc(r, i, 0).i
+		a.link(i, prev, c)
+		i = c
+	}
+
+	return arenaNode{a: a, i: i}
+}
+
+// link inserts the node among the children of the parent after the sibling prev
+func (a *arena) link(parent int32, prev int32, c int32) {
+	a.parents[c] = parent
+	if prev == noNode {
+		a.nexts[c] = a.firsts[parent]
+		a.firsts[parent] = c
+	} else {
+		a.nexts[c] = a.nexts[prev]
+		a.nexts[prev] = c
+	}
+}
+
+// unlink removes the child for the rune from the children of the parent and gives it
+func (a *arena) unlink(parent int32, r rune) (int32, bool) {
+	c, prev := a.child(parent, r)
+	if c == noNode {
+		return noNode, false
+	}
+
+	if prev == noNode {
+		a.firsts[parent] = a.nexts[c]
+	} else {
+		a.nexts[prev] = a.nexts[c]
+	}
+	a.nexts[c] = noNode
+
+	return c, true
+}
+
+// node gives the handle of the index, or nil if it is noNode
+func (an arenaNode) node(i int32) Node {
+	if i == noNode {
+		return nil
+	}
+	return arenaNode{a: an.a, i: i}
+}
+
+// Value gives the rune in the trie node
+func (an arenaNode) Value() rune {
+	return an.a.values[an.i]
+}
+
+// Parent gives the parent of the trie node
+func (an arenaNode) Parent() Node {
+	return an.node(an.a.parents[an.i])
+}
+
+// Children gives a map of the child nodes of the trie node. Changing the map does not change the
+// children
+func (an arenaNode) Children() map[rune]Node {
+	children := make(childNodeMap)
+	for c := an.a.firsts[an.i]; c != noNode; c = an.a.nexts[c] {
+		children[an.a.values[c]] = arenaNode{a: an.a, i: c}
+	}
+	return children
+}
+
+// appendChildren appends the child nodes of the trie node to dst in ascending rune order and returns
+// the extended slice
+func (an arenaNode) appendChildren(dst []Node) []Node {
+	for c := an.a.firsts[an.i]; c != noNode; c = an.a.nexts[c] {
+		dst = append(dst, arenaNode{a: an.a, i: c})
+	}
+	return dst
+}
+
+// Child gives the child node for the rune and whether there is one
+func (an arenaNode) Child(r rune) (Node, bool) {
+	c, _ := an.a.child(an.i, r)
+	if c == noNode {
+		return nil, false
+	}
+	return arenaNode{a: an.a, i: c}, true
+}
+
+// SortedChildren gives the child nodes of the trie node in ascending rune order
+func (an arenaNode) SortedChildren() []Node {
+	children := []Node{}
+	for c := an.a.firsts[an.i]; c != noNode; c = an.a.nexts[c] {
+		children = append(children, arenaNode{a: an.a, i: c})
+	}
+	return children
+}
+
+// HasChildren returns true is the node has children
+func (an arenaNode) HasChildren() bool {
+	return an.a.firsts[an.i] != noNode
+}
+
+// Data gives the data in the node
+func (an arenaNode) Data() interface{} {
+	return an.a.data[an.i]
+}
+
+// setData sets the data in the node
+func (an arenaNode) setData(v interface{}) {
+	an.a.data[an.i] = v
+}
+
+// Path gives the runes from the root to the trie node as a string. The path of the root is empty
+func (an arenaNode) Path() string {
+	runes := make([]rune, an.Depth())
+	i := an.i
+	for j := len(runes) - 1; j >= 0; j-- {
+		runes[j] = an.a.values[i]
+		i = an.a.parents[i]
+	}
+	return string(runes)
+}
+
+// Depth gives the number of runes from the root to the trie node. The depth of the root is 0
+func (an arenaNode) Depth() int {
+	depth := 0
+	for i := an.i; an.a.flags[i]&arenaRoot == 0 && an.a.parents[i] != noNode; i = an.a.parents[i] {
+		depth++
+	}
+	return depth
+}
+
+// IsTerminal returns true if the trie node is a terminating node
+func (an arenaNode) IsTerminal() bool {
+	return an.a.flags[an.i]&arenaTerm != 0
+}
+
+// IsRoot resturn true if the trie node is the root node
+func (an arenaNode) IsRoot() bool {
+	return an.a.flags[an.i]&arenaRoot != 0
+}
+
+// addChild returns the child node for the rune along with true if it had to be added. An added child
+// is not terminating
+func (an arenaNode) addChild(r rune) (Node, bool) {
+	c, prev := an.a.child(an.i, r)
+	if c != noNode {
+		return arenaNode{a: an.a, i: c}, false
+	}
+
+	cNode := an.a.alloc(r, an.i, 0)
+	an.a.link(an.i, prev, cNode.i)
+
+	return cNode, true
+}
+
+// removeChild removes the child node for the rune and frees it along with the nodes below it
+func (an arenaNode) removeChild(r rune) {
+	if c, ok := an.a.unlink(an.i, r); ok {
+		an.a.release(c)
+	}
+}
+
+// setTerm marks the trie node as terminating
+func (an arenaNode) setTerm(term bool) {
+	if term {
+		an.a.flags[an.i] |= arenaTerm
+	} else {
+		an.a.flags[an.i] &^= arenaTerm
+	}
+}
+
+// Equal returns true if the receiver node and the compared to node have the same value and flags,
+// parents with the same value and children with the same values
+func (an arenaNode) Equal(tn2 Node) bool {
+	if tn2 == nil {
+		return false
+	}
+	if an.Value() != tn2.Value() || an.IsRoot() != tn2.IsRoot() || an.IsTerminal() != tn2.IsTerminal() {
+		return false
+	}
+
+	parent, parent2 := an.Parent(), tn2.Parent()
+	if parent == nil || parent2 == nil {
+		return parent == nil && parent2 == nil
+	}
+	if parent.Value() != parent2.Value() {
+		return false
+	}
+
+	children, children2 := an.SortedChildren(), tn2.SortedChildren()
+	if len(children) != len(children2) {
+		return false
+	}
+	for i := range children {
+		if children[i].Value() != children2[i].Value() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// detachChild removes the child node for the rune without freeing it, so it can be attached again
+func (an arenaNode) detachChild(r rune) (Node, bool) {
+	c, ok := an.a.unlink(an.i, r)
+	if !ok {
+		return nil, false
+	}
+	return arenaNode{a: an.a, i: c}, true
+}
+
+// attachChild adds a detached node as a child. Nodes of another trie are copied into the arena
+func (an arenaNode) attachChild(c Node) {
+	if cNode, ok := c.(arenaNode); ok && cNode.a == an.a {
+		_, prev := an.a.child(an.i, cNode.Value())
+		an.a.link(an.i, prev, cNode.i)
+		return
+	}
+	copyNode(an, c)
+}
+
+// release frees a detached node along with the nodes below it
+func (an arenaNode) release(c Node) {
+	if cNode, ok := c.(arenaNode); ok && cNode.a == an.a {
+		an.a.release(cNode.i)
+	}
+}
+
+// handle gives the node wrapped to be handed out
+func (an arenaNode) handle() arenaHandle {
+	return arenaHandle{arenaNode: an, gen: an.a.gens[an.i], value: an.a.values[an.i], data: an.a.data[an.i]}
+}
+
+// expose gives a node to be handed out of the package, wrapping it if it is in an arena
+func expose(n Node) Node {
+	if an, ok := n.(arenaNode); ok {
+		return an.handle()
+	}
+	return n
+}
+
+// detached returns true if the node of the handle was removed from the trie
+func (h arenaHandle) detached() bool {
+	return h.a.gens[h.i] != h.gen
+}
+
+// Value gives the rune in the trie node
+func (h arenaHandle) Value() rune {
+	return h.value
+}
+
+// Parent gives the parent of the trie node
+func (h arenaHandle) Parent() Node {
+	if h.detached() {
+		return nil
+	}
+	if parent := h.a.parents[h.i]; parent != noNode {
+		return arenaNode{a: h.a, i: parent}.handle()
+	}
+	return nil
+}
+
+// Children gives a map of the child nodes of the trie node. Changing the map does not change the
+// children
+func (h arenaHandle) Children() map[rune]Node {
+	if h.detached() {
+		return make(childNodeMap)
+	}
+	children := h.arenaNode.Children()
+	for r, cNode := range children {
+		children[r] = cNode.(arenaNode).handle()
+	}
+	return children
+}
+
+// Child gives the child node for the rune and whether there is one
+func (h arenaHandle) Child(r rune) (Node, bool) {
+	if h.detached() {
+		return nil, false
+	}
+	cNode, ok := h.arenaNode.Child(r)
+	if !ok {
+		return nil, false
+	}
+	return cNode.(arenaNode).handle(), true
+}
+
+// SortedChildren gives the child nodes of the trie node in ascending rune order
+func (h arenaHandle) SortedChildren() []Node {
+	if h.detached() {
+		return []Node{}
+	}
+	children := h.arenaNode.SortedChildren()
+	for i, cNode := range children {
+		children[i] = cNode.(arenaNode).handle()
+	}
+	return children
+}
+
+// HasChildren returns true is the node has children
+func (h arenaHandle) HasChildren() bool {
+	return !h.detached() && h.arenaNode.HasChildren()
+}
+
+// Data gives the data in the node
+func (h arenaHandle) Data() interface{} {
+	if h.detached() {
+		return h.data
+	}
+	return h.arenaNode.Data()
+}
+
+// Path gives the runes from the root to the trie node as a string. The path of the root is empty
+func (h arenaHandle) Path() string {
+	if h.detached() {
+		return ""
+	}
+	return h.arenaNode.Path()
+}
+
+// Depth gives the number of runes from the root to the trie node. The depth of the root is 0
+func (h arenaHandle) Depth() int {
+	if h.detached() {
+		return 0
+	}
+	return h.arenaNode.Depth()
+}
+
+// IsTerminal returns true if the trie node is a terminating node
+func (h arenaHandle) IsTerminal() bool {
+	return !h.detached() && h.arenaNode.IsTerminal()
+}
+
+// IsRoot resturn true if the trie node is the root node
+func (h arenaHandle) IsRoot() bool {
+	return !h.detached() && h.arenaNode.IsRoot()
+}
+
+// Equal returns true if the receiver node and the compared to node have the same value and flags,
+// parents with the same value and children with the same values
+func (h arenaHandle) Equal(tn2 Node) bool {
+	if !h.detached() {
+		return h.arenaNode.Equal(tn2)
+	}
+	return tn2 != nil && tn2.Value() == h.value && !tn2.IsRoot() && !tn2.IsTerminal() &&
+		tn2.Parent() == nil && !tn2.HasChildren()
+}
+
+// copyNode copies the node c and the nodes below it as a child of the node dst
+func copyNode(dst trieNode, c Node) {
+	cNode, _ := dst.addChild(c.Value())
+	cn := cNode.(trieNode)
+	cn.setTerm(c.IsTerminal())
+	cn.setData(c.Data())
+	for _, gc := range c.SortedChildren() {
+		copyNode(cn, gc)
+	}
+}
@@ -0,0 +1,380 @@
+package trie
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newArenaTrie creates a trie in an arena with a suffix index holding the words, with their index as
+// data
+func newArenaTrie(t *testing.T, words ...string) *Trie {
+	tr := New("arena", WithArena(), WithSuffixIndex())
+	for i, word := range words {
+		_, err := tr.Add(word, i)
+		require.NoError(t, err)
+	}
+	return tr
+}
+
+func TestArenaQueries(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Fun      string
+		Input    string
+		ExpWords []string
+	}{
+		{
+			Name:     "all words are returned",
+			Fun:      "Words",
+			ExpWords: []string{"car", "card", "care", "cart", "cat", "do", "dog"},
+		},
+		{
+			Name:     "words with prefix are returned",
+			Fun:      "WordsWithPrefix",
+			Input:    "car",
+			ExpWords: []string{"car", "card", "care", "cart"},
+		},
+		{
+			Name:     "missing prefix returns no words",
+			Fun:      "WordsWithPrefix",
+			Input:    "cow",
+			ExpWords: []string{},
+		},
+		{
+			Name:     "words with suffix are returned",
+			Fun:      "WordsWithSuffix",
+			Input:    "t",
+			ExpWords: []string{"cart", "cat"},
+		},
+		{
+			Name:     "longest prefix is returned",
+			Fun:      "LongestPrefixOf",
+			Input:    "cartwheel",
+			ExpWords: []string{"cart"},
+		},
+		{
+			Name:     "fuzzy matches are returned",
+			Fun:      "FuzzyFind",
+			Input:    "cer",
+			ExpWords: []string{"car"},
+		},
+	}
+
+	tr := newArenaTrie(t, "car", "card", "care", "cart", "cat", "dog", "do")
+
+	for _, test := range cases {
+		var words []string
+		var err error
+
+		if test.Fun == "Words" {
+			words = tr.Words()
+		} else if test.Fun == "WordsWithPrefix" {
+			words = tr.WordsWithPrefix(test.Input)
+		} else if test.Fun == "WordsWithSuffix" {
+			words, err = tr.WordsWithSuffix(test.Input)
+		} else if test.Fun == "LongestPrefixOf" {
+			var word string
+			word, err = tr.LongestPrefixOf(test.Input)
+			words = []string{word}
+		} else if test.Fun == "FuzzyFind" {
+			words = []string{}
+			for _, match := range tr.FuzzyFind(test.Input, 1) {
+				words = append(words, match.Word)
+			}
+		}
+
+		require.NoError(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, words, test.Name)
+	}
+}
+
+func TestArenaRemove(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Remove   []string
+		ExpWords []string
+		ExpNodes int
+	}{
+		{
+			Name:     "leaf word prunes its branch",
+			Remove:   []string{"cart"},
+			ExpWords: []string{"car", "care", "dog"},
+			ExpNodes: 8,
+		},
+		{
+			Name:     "word with children keeps its node",
+			Remove:   []string{"car"},
+			ExpWords: []string{"care", "cart", "dog"},
+			ExpNodes: 9,
+		},
+		{
+			Name:     "removing every word leaves the root",
+			Remove:   []string{"car", "care", "cart", "dog"},
+			ExpWords: []string{},
+			ExpNodes: 1,
+		},
+	}
+
+	for _, test := range cases {
+		tr := newArenaTrie(t, "car", "care", "cart", "dog")
+		for _, word := range test.Remove {
+			require.NoError(t, tr.Remove(word), test.Name)
+			_, err := tr.Find(word)
+			assert.ErrorIs(t, err, ErrNotFound, test.Name)
+		}
+
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		assert.Equal(t, test.ExpNodes, tr.Stats().Nodes, test.Name)
+		suffixed, err := tr.WordsWithSuffix("")
+		require.NoError(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, suffixed, test.Name)
+	}
+}
+
+func TestArenaBatch(t *testing.T) {
+	tr := newArenaTrie(t, "car", "cat", "do", "dog")
+	before := tr.String()
+
+	err := tr.Batch(func(tx *Tx) error {
+		for _, word := range []string{"cat", "dog", "do", "car"} {
+			if err := tx.Remove(word); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Add("cow", 1); err != nil {
+			return err
+		}
+		return errors.New("bad line")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, before, tr.String())
+	assert.ElementsMatch(t, []string{"car", "cat", "do", "dog"}, tr.Words())
+	n, err := tr.Find("dog")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n.Data())
+}
+
+func TestArenaReuse(t *testing.T) {
+	var cases = []struct {
+		Name   string
+		Handle func(tr *Trie, word string, data int)
+	}{
+		{
+			Name: "nodes of words added with Put are reused",
+			Handle: func(tr *Trie, word string, data int) {
+				_, _, err := tr.Put(word, data)
+				require.NoError(t, err)
+			},
+		},
+		{
+			Name: "nodes returned by Add are reused",
+			Handle: func(tr *Trie, word string, data int) {
+				_, err := tr.Add(word, data)
+				require.NoError(t, err)
+			},
+		},
+		{
+			Name: "nodes returned by Find and Update are reused",
+			Handle: func(tr *Trie, word string, data int) {
+				_, err := tr.Update(word, func(interface{}, bool) (interface{}, bool) { return data, true })
+				require.NoError(t, err)
+				_, err = tr.Find(word)
+				require.NoError(t, err)
+			},
+		},
+		{
+			Name: "nodes visited by Walk are reused",
+			Handle: func(tr *Trie, word string, data int) {
+				_, err := tr.Add(word, data)
+				require.NoError(t, err)
+				tr.Walk(func([]rune, Node) WalkAction { return Continue })
+			},
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("arena", WithArena())
+		a := tr.root().(arenaNode).a
+
+		var handles []Node
+		for round := 0; round < 3; round++ {
+			for i := 0; i < 1000; i++ {
+				word := fmt.Sprintf("word%d", i)
+				test.Handle(tr, word, i)
+				n, err := tr.Find(word)
+				require.NoError(t, err, test.Name)
+				handles = append(handles, n)
+			}
+			for i := 0; i < 1000; i++ {
+				require.NoError(t, tr.Remove(fmt.Sprintf("word%d", i)), test.Name)
+			}
+			assert.Equal(t, 1, tr.Stats().Nodes, test.Name)
+		}
+
+		// The root, the shared prefix and the 10, 90 and 900 nodes of the numbers are all ever in use
+		assert.Equal(t, 1005, len(a.values), test.Name)
+		for i, n := range handles {
+			assert.Equal(t, i%1000, n.Data(), test.Name)
+			assert.False(t, n.IsTerminal(), test.Name)
+		}
+	}
+}
+
+func TestArenaHandle(t *testing.T) {
+	tr := New("arena", WithArena())
+	for i, word := range []string{"car", "cart", "cat"} {
+		_, err := tr.Add(word, i)
+		require.NoError(t, err)
+	}
+
+	n, err := tr.Find("cart")
+	require.NoError(t, err)
+	kept, err := tr.Find("cat")
+	require.NoError(t, err)
+	root := tr.Root
+	c, ok := root.Child('c')
+	require.True(t, ok)
+
+	require.NoError(t, tr.Remove("cart"))
+	require.NoError(t, tr.Remove("car"))
+	_, _, err = tr.Put("cat", 5)
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		_, err := tr.Add(fmt.Sprintf("word%d", i), i)
+		require.NoError(t, err)
+	}
+
+	var cases = []struct {
+		Name string
+		Fun  string
+		Out  interface{}
+	}{
+		{
+			Name: "removed node keeps its rune",
+			Fun:  "Value",
+			Out:  't',
+		},
+		{
+			Name: "removed node keeps its data",
+			Fun:  "Data",
+			Out:  1,
+		},
+		{
+			Name: "removed node is detached",
+			Fun:  "Detached",
+			Out:  []interface{}{"", nil, false, false},
+		},
+		{
+			Name: "node still in the trie sees its children",
+			Fun:  "Children",
+			Out:  []string{"ca"},
+		},
+		{
+			Name: "node still in the trie sees its data change",
+			Fun:  "KeptData",
+			Out:  5,
+		},
+	}
+
+	for _, test := range cases {
+		var op interface{}
+
+		if test.Fun == "Value" {
+			op = n.Value()
+		} else if test.Fun == "Data" {
+			op = n.Data()
+		} else if test.Fun == "Detached" {
+			op = []interface{}{n.Path(), n.Parent(), n.IsTerminal(), n.HasChildren()}
+		} else if test.Fun == "Children" {
+			paths := []string{}
+			for _, cNode := range c.SortedChildren() {
+				paths = append(paths, cNode.Path())
+			}
+			op = paths
+		} else if test.Fun == "KeptData" {
+			op = kept.Data()
+		}
+
+		assert.Equal(t, test.Out, op, test.Name)
+	}
+	assert.ElementsMatch(t, []string{"cat"}, tr.WordsWithPrefix("c"))
+}
+
+func TestArenaOptionOrder(t *testing.T) {
+	var cases = []struct {
+		Name string
+		Opts []Option
+	}{
+		{
+			Name: "arena before suffix index",
+			Opts: []Option{WithArena(), WithSuffixIndex()},
+		},
+		{
+			Name: "arena after suffix index",
+			Opts: []Option{WithSuffixIndex(), WithArena()},
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("arena", test.Opts...)
+		_, ok := tr.root().(arenaNode)
+		assert.True(t, ok, test.Name)
+		_, ok = tr.suffix.root().(arenaNode)
+		assert.True(t, ok, test.Name)
+	}
+}
+
+func TestArenaBuilderAndParallel(t *testing.T) {
+	words := []string{"a", "ab", "abc", "b", "bc", "c"}
+
+	b := NewBuilder("arena", WithArena())
+	for _, word := range words {
+		require.NoError(t, b.Add(word, ""))
+	}
+	built := b.Trie()
+	_, ok := built.root().(arenaNode)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, words, built.Words())
+
+	input := strings.Join(words, "\n")
+	par, err := NewFromReader(strings.NewReader(input), LoadOptions{Workers: 3, Options: []Option{WithArena()}})
+	require.NoError(t, err)
+	assert.Empty(t, Diff(built, par))
+	root := par.root().(arenaNode)
+	for _, n := range root.SortedChildren() {
+		assert.Equal(t, root, n.Parent())
+		assert.Equal(t, root.a, n.(arenaNode).a)
+	}
+}
+
+func BenchmarkArena(b *testing.B) {
+	words := make([]string, 100000)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%08d", i)
+	}
+
+	b.Run("Nodes", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tr := New("bench")
+			for _, word := range words {
+				tr.Add(word, nil)
+			}
+		}
+	})
+	b.Run("Arena", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			tr := New("bench", WithArena())
+			for _, word := range words {
+				tr.Add(word, nil)
+			}
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package trie
+
+import (
+	"fmt"
+)
+
+// undoLog records how to revert every node change made during a batch, in the order they were made
+type undoLog struct {
+	steps []func()
+
+	// events holds the events of the batch until it succeeds
+	events []Change
+
+	// commits holds the steps finishing the changes once the batch succeeds
+	commits []func()
+}
+
+// record adds a step reverting a change. Nothing is recorded outside of a batch
+func (l *undoLog) record(step func()) {
+	if l != nil {
+		l.steps = append(l.steps, step)
+	}
+}
+
+// onCommit adds a step finishing a change once the batch succeeds
+func (l *undoLog) onCommit(step func()) {
+	if l != nil {
+		l.commits = append(l.commits, step)
+	}
+}
+
+// rollback reverts the changes recorded, most recent first
+func (l *undoLog) rollback() {
+	for i := len(l.steps) - 1; i >= 0; i-- {
+		l.steps[i]()
+	}
+	l.steps = nil
+	l.events = nil
+	l.commits = nil
+}
+
+// commit finishes the changes recorded
+func (l *undoLog) commit() {
+	for _, step := range l.commits {
+		step()
+	}
+	l.steps = nil
+	l.commits = nil
+}
+
+// Tx applies changes to a trie during Batch. The changes are visible straight away and are
+// reverted if the batch fails
+type Tx struct {
+	trie *Trie
+	done bool
+}
+
+// Batch calls fn with a transaction whose changes are all reverted if fn returns an error or panics,
+// including the nodes pruned by Remove. Batches cannot be nested
+func (t *Trie) Batch(fn func(tx *Tx) error) error {
+	if t.undo != nil {
+		return fmt.Errorf("batch already in progress on trie %s", t.Name)
+	}
+
+	log := &undoLog{}
+	t.setUndoLog(log)
+	tx := &Tx{trie: t}
+
+	committed := false
+	defer func() {
+		tx.done = true
+		if !committed {
+			log.rollback()
+		}
+		t.setUndoLog(nil)
+
+		// The events of the batch are only delivered once all of its changes are kept
+		if committed {
+			log.commit()
+			for _, e := range log.events {
+				t.emit(e)
+			}
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+
+	return nil
+}
+
+// setUndoLog records the changes of the trie and its suffix index in log, or stops recording if it
+// is nil
+func (t *Trie) setUndoLog(log *undoLog) {
+	t.undo = log
+	if t.suffix != nil {
+		t.suffix.undo = log
+	}
+}
+
+// Add adds the word with its data to the trie as Trie.Add does
+func (tx *Tx) Add(word string, data interface{}) (Node, error) {
+	if tx.done {
+		return nil, errorf(ErrClosed, "transaction is already finished")
+	}
+	return tx.trie.Add(word, data)
+}
+
+// Remove removes the word from the trie as Trie.Remove does
+func (tx *Tx) Remove(word string) error {
+	if tx.done {
+		return errorf(ErrClosed, "transaction is already finished")
+	}
+	return tx.trie.Remove(word)
+}
+
+// Put adds or replaces the word with its data as Trie.Put does
+func (tx *Tx) Put(word string, data interface{}) (interface{}, bool, error) {
+	if tx.done {
+		return nil, false, errorf(ErrClosed, "transaction is already finished")
+	}
+	return tx.trie.Put(word, data)
+}
+
+// Update changes the data of the word as Trie.Update does
+func (tx *Tx) Update(word string, fn UpdateFunc) (Node, error) {
+	if tx.done {
+		return nil, errorf(ErrClosed, "transaction is already finished")
+	}
+	return tx.trie.Update(word, fn)
+}
+
+// Find checks if the word is in the trie, including the changes of the transaction
+func (tx *Tx) Find(word string) (Node, error) {
+	return tx.trie.Find(word)
+}
+
+// afterCommit calls fn once the batch succeeds, dropping it if the batch fails, or straight away
+// outside of a batch
+func (t *Trie) afterCommit(fn func()) {
+	if t.undo == nil {
+		fn()
+		return
+	}
+	t.undo.onCommit(fn)
+}
+
+// addChild gets or adds the child of the node for the rune, recording the addition in a batch
+func (t *Trie) addChild(n Node, r rune) Node {
+	tn := n.(trieNode)
+	cNode, added := tn.addChild(r)
+	if added {
+		t.undo.record(func() { tn.removeChild(r) })
+	}
+	return cNode
+}
+
+// removeChild removes the child of the node for the rune. In a batch the child is detached so it can
+// be attached again, and only freed once the batch succeeds
+func (t *Trie) removeChild(n Node, r rune) {
+	tn := n.(trieNode)
+	if t.undo == nil {
+		tn.removeChild(r)
+		return
+	}
+
+	if cNode, ok := tn.detachChild(r); ok {
+		t.undo.record(func() { tn.attachChild(cNode) })
+		t.undo.onCommit(func() { tn.release(cNode) })
+	}
+}
+
+// setTerm sets the terminal flag of the node, recording the previous flag in a batch
+func (t *Trie) setTerm(n Node, term bool) {
+	tn := n.(trieNode)
+	if old := tn.IsTerminal(); old != term {
+		t.undo.record(func() { tn.setTerm(old) })
+	}
+	tn.setTerm(term)
+}
+
+// setData sets the data of the node, recording the previous data in a batch
+func (t *Trie) setData(n Node, data interface{}) {
+	tn := n.(trieNode)
+	if t.undo != nil {
+		old := tn.Data()
+		t.undo.record(func() { tn.setData(old) })
+	}
+	tn.setData(data)
+}
+
+// setSpelling maps the key to the word, or forgets the key if the word is empty, recording the
+// previous spelling in a batch
+func (t *Trie) setSpelling(key string, word string) {
+	if t.spellings == nil {
+		return
+	}
+
+	if t.undo != nil {
+		old, ok := t.spellings[key]
+		t.undo.record(func() {
+			if ok {
+				t.spellings[key] = old
+			} else {
+				delete(t.spellings, key)
+			}
+		})
+	}
+
+	if word == "" {
+		delete(t.spellings, key)
+	} else {
+		t.spellings[key] = word
+	}
+}
@@ -0,0 +1,143 @@
+package trie
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	words := map[string]interface{}{"car": 1, "card": 2, "care": 3, "cat": 4, "dog": 5}
+	build := func() *Trie {
+		tr := New("batch", WithSuffixIndex(), WithCaseFolding())
+		for word, data := range words {
+			_, err := tr.Add(word, data)
+			require.NoError(t, err)
+		}
+		return tr
+	}
+
+	var cases = []struct {
+		Name      string
+		Ops       func(tx *Tx) error
+		Panic     bool
+		ExpWords  []string
+		ExpectErr string
+	}{
+		{
+			Name: "successful batch is kept",
+			Ops: func(tx *Tx) error {
+				if _, err := tx.Add("Cart", 6); err != nil {
+					return err
+				}
+				if _, _, err := tx.Put("car", 7); err != nil {
+					return err
+				}
+				return tx.Remove("dog")
+			},
+			ExpWords: []string{"Cart", "car", "card", "care", "cat"},
+		},
+		{
+			Name: "failed add rolls back added and pruned nodes",
+			Ops: func(tx *Tx) error {
+				if _, err := tx.Add("Cartwheel", 6); err != nil {
+					return err
+				}
+				if err := tx.Remove("dog"); err != nil {
+					return err
+				}
+				if err := tx.Remove("cat"); err != nil {
+					return err
+				}
+				if _, _, err := tx.Put("card", "replaced"); err != nil {
+					return err
+				}
+				_, err := tx.Add("care", 8)
+				return err
+			},
+			ExpectErr: "word care already exists in trie",
+		},
+		{
+			Name: "removed prefix and added extension are rolled back",
+			Ops: func(tx *Tx) error {
+				if err := tx.Remove("car"); err != nil {
+					return err
+				}
+				if err := tx.Remove("card"); err != nil {
+					return err
+				}
+				if _, err := tx.Add("c", 9); err != nil {
+					return err
+				}
+				if _, err := tx.Update("dogs", func(interface{}, bool) (interface{}, bool) { return 1, true }); err != nil {
+					return err
+				}
+				return errors.New("bad line")
+			},
+			ExpectErr: "bad line",
+		},
+		{
+			Name: "panic rolls back",
+			Ops: func(tx *Tx) error {
+				if err := tx.Remove("dog"); err != nil {
+					return err
+				}
+				panic("bad line")
+			},
+			Panic: true,
+		},
+	}
+
+	for _, test := range cases {
+		tr := build()
+
+		var err error
+		if test.Panic {
+			assert.Panics(t, func() { _ = tr.Batch(test.Ops) }, test.Name)
+		} else {
+			err = tr.Batch(test.Ops)
+		}
+
+		if test.ExpectErr != "" || test.Panic {
+			if !test.Panic {
+				assert.EqualError(t, err, test.ExpectErr, test.Name)
+			}
+
+			want := build()
+			assert.Empty(t, Diff(want, tr), test.Name)
+			assert.Equal(t, want.Stats(), tr.Stats(), test.Name)
+			assert.Equal(t, want.spellings, tr.spellings, test.Name)
+			assert.Empty(t, Diff(want.suffix, tr.suffix), test.Name)
+			assert.Equal(t, want.suffix.Stats(), tr.suffix.Stats(), test.Name)
+			continue
+		}
+
+		require.NoError(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		suffixed, err := tr.WordsWithSuffix("t")
+		require.NoError(t, err, test.Name)
+		assert.ElementsMatch(t, []string{"Cart", "cat"}, suffixed, test.Name)
+	}
+}
+
+func TestBatchFinished(t *testing.T) {
+	tr := New("batch")
+
+	var saved *Tx
+	err := tr.Batch(func(tx *Tx) error {
+		saved = tx
+		return tr.Batch(func(*Tx) error { return nil })
+	})
+	assert.EqualError(t, err, "batch already in progress on trie batch")
+
+	_, err = saved.Add("a", nil)
+	assert.EqualError(t, err, "transaction is already finished")
+	assert.Empty(t, tr.Words())
+
+	// The trie records nothing once the batch is over
+	_, err = tr.Add("a", nil)
+	require.NoError(t, err)
+	assert.Nil(t, tr.undo)
+}
@@ -0,0 +1,190 @@
+package trie
+
+import (
+	"fmt"
+)
+
+// Builder builds a trie from words added in ascending order of their keys. Every word only records
+// the nodes after the prefix it shares with the previous word, so no word descends from the root.
+// The nodes are recorded level by level in the breadth first order of a FrozenTrie, so Freeze
+// needs no other copy of the trie and Trie creates every node once
+type Builder struct {
+	trie *Trie
+	prev string
+	// levels holds the nodes of every depth in breadth first order, beginning with the root
+	levels []builderLevel
+	// stack holds the index within its level of every node along the previous key
+	stack []int32
+	words int
+	done  bool
+	built bool
+}
+
+// builderLevel holds the nodes of one depth of a Builder
+type builderLevel struct {
+	labels []rune
+	term   []bool
+	data   []interface{}
+	// childStart holds the index in the next level of the first child of every node. The
+	// children of a node end where those of the next node begin
+	childStart []int32
+}
+
+// add records a node with the rune and gives its index in the level
+func (l *builderLevel) add(r rune, next int) int32 {
+	l.labels = append(l.labels, r)
+	l.term = append(l.term, false)
+	l.data = append(l.data, nil)
+	l.childStart = append(l.childStart, int32(next))
+	return int32(len(l.labels) - 1)
+}
+
+// NewBuilder creates a builder for a trie with the name and options specified. Capacity options
+// cannot be used, as a builder cannot evict words
+func NewBuilder(name string, opts ...Option) *Builder {
+	b := &Builder{
+		trie:   New(name, opts...),
+		levels: []builderLevel{{}},
+		stack:  []int32{0},
+	}
+	b.levels[0].add(0, 0)
+	return b
+}
+
+// Add adds the word with its data to the trie. An error is returned if the key of the word is not
+// greater than the key of the previous word, which also rejects repeated words
+func (b *Builder) Add(word string, data interface{}) error {
+	if b.done {
+		return errorf(ErrClosed, "builder is already finished")
+	}
+	if b.trie.usage != nil {
+		return fmt.Errorf("capacity options cannot be used with a builder")
+	}
+
+	key := b.trie.key(word)
+	if len(key) == 0 {
+		return errorf(ErrEmptyKey, "no string to add")
+	}
+	if b.words > 0 && key <= b.prev {
+		return fmt.Errorf("word %s is not after %s", word, b.trie.spelling(b.prev))
+	}
+
+	runes := []rune(key)
+	shared := 0
+	for _, r := range b.prev {
+		if shared == len(runes) || runes[shared] != r {
+			break
+		}
+		shared++
+	}
+
+	// The suffix index is updated first so nothing is recorded if it cannot take the word
+	if err := b.trie.indexKey(key, word); err != nil {
+		return fmt.Errorf("could not add word %s to suffix index: %s", word, err)
+	}
+
+	b.stack = b.stack[:shared+1]
+	for depth := shared + 1; depth <= len(runes); depth++ {
+		if depth == len(b.levels) {
+			b.levels = append(b.levels, builderLevel{})
+		}
+		next := 0
+		if depth+1 < len(b.levels) {
+			next = len(b.levels[depth+1].labels)
+		}
+		b.stack = append(b.stack, b.levels[depth].add(runes[depth-1], next))
+	}
+
+	level, i := &b.levels[len(runes)], b.stack[len(runes)]
+	level.term[i] = true
+	level.data[i] = data
+	b.prev = key
+	b.words++
+
+	return nil
+}
+
+// Trie finishes the builder and returns the trie built. The builder cannot be used afterwards
+func (b *Builder) Trie() *Trie {
+	b.done = true
+	if b.built {
+		return b.trie
+	}
+	b.built = true
+
+	// The nodes of every level are created under the nodes of the level above, whose children
+	// are contiguous in the level
+	parents := []Node{b.trie.root()}
+	for depth := 1; depth < len(b.levels); depth++ {
+		above, level := &b.levels[depth-1], &b.levels[depth]
+		nodes := make([]Node, len(level.labels))
+		for p, parent := range parents {
+			tn := parent.(trieNode)
+			for c := above.childStart[p]; c < b.childEnd(depth-1, p); c++ {
+				cNode, _ := tn.addChild(level.labels[c])
+				if level.term[c] {
+					cNode.(trieNode).setTerm(true)
+					cNode.(trieNode).setData(level.data[c])
+				}
+				nodes[c] = cNode
+			}
+		}
+		parents = nodes
+	}
+
+	return b.trie
+}
+
+// Freeze finishes the builder and returns the trie built in its frozen form without creating its
+// nodes. The builder cannot be used afterwards
+func (b *Builder) Freeze() *FrozenTrie {
+	b.done = true
+
+	nodes := 0
+	offsets := make([]int32, len(b.levels)+1)
+	for depth, level := range b.levels {
+		offsets[depth] = int32(nodes)
+		nodes += len(level.labels)
+	}
+	offsets[len(b.levels)] = int32(nodes)
+
+	ft := &FrozenTrie{
+		Name:    b.trie.Name,
+		keyMode: b.trie.keyMode,
+		labels:  make([]rune, 0, nodes),
+		first:   make([]int32, 0, nodes+1),
+		term:    make([]bool, 0, nodes),
+		data:    make([]interface{}, 0, nodes),
+		words:   b.words,
+	}
+	for depth, level := range b.levels {
+		ft.labels = append(ft.labels, level.labels...)
+		ft.term = append(ft.term, level.term...)
+		ft.data = append(ft.data, level.data...)
+		for _, start := range level.childStart {
+			ft.first = append(ft.first, offsets[depth+1]+start)
+		}
+	}
+	ft.first = append(ft.first, int32(nodes))
+
+	if b.trie.spellings != nil {
+		ft.spellings = make(map[string]string, len(b.trie.spellings))
+		for key, word := range b.trie.spellings {
+			ft.spellings[key] = word
+		}
+	}
+
+	return ft
+}
+
+// childEnd gives the index in the next level after the last child of node i of the level at depth
+func (b *Builder) childEnd(depth int, i int) int32 {
+	level := &b.levels[depth]
+	if i+1 < len(level.childStart) {
+		return level.childStart[i+1]
+	}
+	if depth+1 < len(b.levels) {
+		return int32(len(b.levels[depth+1].labels))
+	}
+	return 0
+}
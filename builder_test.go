@@ -0,0 +1,128 @@
+package trie
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Words     []string
+		Opts      []Option
+		ExpectErr string
+	}{
+		{
+			Name:  "sorted words build the same trie as Add",
+			Words: []string{"a", "ab", "abc", "abd", "b", "ba", "c"},
+		},
+		{
+			Name:  "key modes order by key",
+			Words: []string{"Apple", "apricot", "Banana"},
+			Opts:  []Option{WithCaseFolding(), WithSuffixIndex()},
+		},
+		{
+			Name:      "out of order word throws error",
+			Words:     []string{"b", "a"},
+			ExpectErr: "word a is not after b",
+		},
+		{
+			Name:      "repeated word throws error",
+			Words:     []string{"a", "a"},
+			ExpectErr: "word a is not after a",
+		},
+		{
+			Name:  "words sharing no prefix with the previous word",
+			Words: []string{"abc", "abd", "b", "bcd", "bce", "c"},
+		},
+		{
+			Name:      "capacity options throw error",
+			Words:     []string{"a"},
+			Opts:      []Option{WithMaxEntries(1, LRU)},
+			ExpectErr: "capacity options cannot be used with a builder",
+		},
+		{
+			Name:      "empty word throws error",
+			Words:     []string{""},
+			ExpectErr: "no string to add",
+		},
+	}
+
+	for _, test := range cases {
+		b := NewBuilder("test", test.Opts...)
+		exp := New("test", test.Opts...)
+
+		var err error
+		for i, word := range test.Words {
+			if err = b.Add(word, i); err != nil {
+				break
+			}
+			_, addErr := exp.Add(word, i)
+			require.Empty(t, addErr, test.Name)
+		}
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		require.Empty(t, err, test.Name)
+
+		assert.Equal(t, exp.Freeze(), b.Freeze(), test.Name)
+		tr := b.Trie()
+		assert.Equal(t, Patch{}, Diff(exp, tr), test.Name)
+		assert.Equal(t, exp.String(), tr.String(), test.Name)
+		assert.ElementsMatch(t, exp.Words(), tr.Words(), test.Name)
+		if exp.suffix != nil {
+			assert.Equal(t, exp.suffix.String(), tr.suffix.String(), test.Name)
+		}
+
+		err = b.Add("zzz", nil)
+		require.NotEmpty(t, err, test.Name)
+		assert.Contains(t, err.Error(), "builder is already finished", test.Name)
+	}
+}
+
+func TestBuilderBuiltTrieIsMutable(t *testing.T) {
+	b := NewBuilder("test")
+	for _, word := range []string{"ab", "abc", "b"} {
+		require.Empty(t, b.Add(word, nil))
+	}
+	tr := b.Trie()
+
+	require.Empty(t, tr.Remove("abc"))
+	_, err := tr.Add("abd", nil)
+	require.Empty(t, err)
+	_, err = tr.Add("bc", nil)
+	require.Empty(t, err)
+
+	assert.ElementsMatch(t, []string{"ab", "abd", "b", "bc"}, tr.Words())
+}
+
+func BenchmarkBuilder(b *testing.B) {
+	words := make([]string, 100000)
+	for i := range words {
+		words[i] = fmt.Sprintf("word%08d", i)
+	}
+
+	b.Run("Add", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			tr := New("bench")
+			for _, word := range words {
+				tr.Add(word, nil)
+			}
+		}
+	})
+	b.Run("Builder", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bld := NewBuilder("bench")
+			for _, word := range words {
+				bld.Add(word, nil)
+			}
+			bld.Trie()
+		}
+	})
+}
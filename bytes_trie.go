@@ -0,0 +1,120 @@
+package trie
+
+import (
+	"fmt"
+)
+
+// BytesTrie defines a trie keyed on byte slices instead of runes. Every byte of a key is stored
+// as its own node, so binary and non UTF-8 keys never collide
+type BytesTrie struct {
+	trie *Trie
+}
+
+// NewBytes creates a byte keyed trie with name specified. If no name is specified then "Trie"
+// is used
+func NewBytes(name string) *BytesTrie {
+	return &BytesTrie{
+		trie: New(name),
+	}
+}
+
+// Name gives the name of the trie
+func (bt *BytesTrie) Name() string {
+	return bt.trie.Name
+}
+
+// Root gives the root node of the trie. Node values are the bytes of the keys
+func (bt *BytesTrie) Root() Node {
+	return bt.trie.Root
+}
+
+// Find checks if the trie has the key and returns the terminating node of the key
+func (bt *BytesTrie) Find(key []byte) (Node, error) {
+	if len(key) == 0 {
+		return nil, errorf(ErrEmptyKey, "no key to find")
+	}
+
+	termNode, err := bt.trie.findAtNode(bt.trie.root(), bytesToRunes(key), 0)
+	if err != nil {
+		return nil, fmt.Errorf("key %x not found: %w", key, err)
+	}
+
+	return termNode, nil
+}
+
+// Add adds a key to the trie and returns the terminating node. If the key already exists in the
+// trie an error is returned
+func (bt *BytesTrie) Add(key []byte, data interface{}) (Node, error) {
+	if len(key) == 0 {
+		return nil, errorf(ErrEmptyKey, "no key to add")
+	}
+
+	termNode, err := bt.trie.addAtNode(bt.trie.root(), bytesToRunes(key), data)
+	if err != nil {
+		return nil, errorf(ErrExists, "key %x already exists in trie", key)
+	}
+	return termNode, nil
+}
+
+// Remove removes the key from the trie. An error is returned if the key is not in the trie
+func (bt *BytesTrie) Remove(key []byte) error {
+	termNode, err := bt.Find(key)
+	if err != nil {
+		return fmt.Errorf("could not find key %x in trie: %w", key, err)
+	}
+
+	bt.trie.unterminate(termNode)
+
+	return nil
+}
+
+// Keys returns an array of keys in the trie
+func (bt *BytesTrie) Keys() [][]byte {
+	return bt.KeysWithPrefix(nil)
+}
+
+// KeysWithPrefix returns an array of keys in the trie that begin with prefix
+func (bt *BytesTrie) KeysWithPrefix(prefix []byte) [][]byte {
+	keys := [][]byte{}
+
+	if n := bt.trie.nodeAtPrefix(bytesToRunes(prefix)); n != nil {
+		keysAtNode(n, append([]byte{}, prefix...), &keys, &childStack{})
+	}
+
+	return keys
+}
+
+// LongestPrefixOf returns the longest key in the trie that is a prefix of key. An error is
+// returned if no key in the trie is a prefix of key
+func (bt *BytesTrie) LongestPrefixOf(key []byte) ([]byte, error) {
+	_, length := bt.trie.longestPrefixAtNode(bt.trie.root(), bytesToRunes(key))
+	if length == 0 {
+		return nil, errorf(ErrNotFound, "no prefix of %x found in trie", key)
+	}
+
+	return append([]byte{}, key[:length]...), nil
+}
+
+// keysAtNode adds all keys that occur after the node specified
+func keysAtNode(n Node, tillThis []byte, keys *[][]byte, children *childStack) {
+	if n.IsTerminal() {
+		*keys = append(*keys, append([]byte{}, tillThis...))
+	}
+
+	start, end := children.push(n, false)
+	for i := start; i < end; i++ {
+		cNode := (*children)[i]
+		keysAtNode(cNode, append(tillThis, byte(cNode.Value())), keys, children)
+	}
+	children.pop(start)
+}
+
+// bytesToRunes maps every byte to the rune of the same value so that keys can be stored in the
+// rune keyed nodes
+func bytesToRunes(b []byte) []rune {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return runes
+}
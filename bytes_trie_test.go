@@ -0,0 +1,150 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBytesTrieAddFind(t *testing.T) {
+	// Both keys turn into "�" when converted to runes
+	invalid1 := []byte{0xff}
+	invalid2 := []byte{0xfe}
+
+	var cases = []struct {
+		Name      string
+		Input     []byte
+		ExpectErr string
+	}{
+		{
+			Name:  "invalid UTF-8 key is found",
+			Input: invalid1,
+		},
+		{
+			Name:  "colliding invalid UTF-8 key is found",
+			Input: invalid2,
+		},
+		{
+			Name:  "binary key with zero bytes is found",
+			Input: []byte{0x00, 0x01, 0x00},
+		},
+		{
+			Name:      "prefix of a key throws error",
+			Input:     []byte{0x00, 0x01},
+			ExpectErr: "key 0001 not found",
+		},
+		{
+			Name:      "empty key throws error",
+			Input:     []byte{},
+			ExpectErr: "no key to find",
+		},
+	}
+
+	bt := NewBytes("test")
+	for i, key := range [][]byte{invalid1, invalid2, {0x00, 0x01, 0x00}} {
+		_, err := bt.Add(key, i)
+		require.Empty(t, err)
+	}
+	_, err := bt.Add(invalid1, "")
+	require.NotEmpty(t, err, "adding an existing key should throw error")
+	assert.Contains(t, err.Error(), "key ff already exists in trie")
+
+	for _, test := range cases {
+		_, err := bt.Find(test.Input)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+	}
+	assert.Len(t, bt.Keys(), 3)
+}
+
+func TestBytesTrieRemove(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Input     []byte
+		ExpKeys   [][]byte
+		ExpectErr string
+	}{
+		{
+			Name:    "key is removed and its prefix key is kept",
+			Input:   []byte{0xca, 0xfe},
+			ExpKeys: [][]byte{{0xca}},
+		},
+		{
+			Name:    "prefix key is removed and the longer key is kept",
+			Input:   []byte{0xca},
+			ExpKeys: [][]byte{{0xca, 0xfe}},
+		},
+		{
+			Name:      "missing key throws error",
+			Input:     []byte{0xbe, 0xef},
+			ExpectErr: "could not find key beef",
+		},
+	}
+
+	for _, test := range cases {
+		bt := NewBytes("test")
+		_, err := bt.Add([]byte{0xca}, nil)
+		require.Empty(t, err, test.Name)
+		_, err = bt.Add([]byte{0xca, 0xfe}, nil)
+		require.Empty(t, err, test.Name)
+
+		err = bt.Remove(test.Input)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpKeys, bt.Keys(), test.Name)
+	}
+}
+
+func TestBytesTriePrefix(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Input      []byte
+		ExpKeys    [][]byte
+		ExpLongest []byte
+		ExpectErr  string
+	}{
+		{
+			Name:       "keys with prefix and longest prefix are returned",
+			Input:      []byte{0x80, 0x81},
+			ExpKeys:    [][]byte{{0x80, 0x81}, {0x80, 0x81, 0x82}},
+			ExpLongest: []byte{0x80, 0x81},
+		},
+		{
+			Name:      "key without any prefix in trie throws error",
+			Input:     []byte{0x7f},
+			ExpKeys:   [][]byte{},
+			ExpectErr: "no prefix of 7f found",
+		},
+	}
+
+	bt := NewBytes("test")
+	for _, key := range [][]byte{{0x80}, {0x80, 0x81}, {0x80, 0x81, 0x82}, {0x90}} {
+		_, err := bt.Add(key, nil)
+		require.Empty(t, err)
+	}
+
+	for _, test := range cases {
+		assert.ElementsMatch(t, test.ExpKeys, bt.KeysWithPrefix(test.Input), test.Name)
+
+		longest, err := bt.LongestPrefixOf(test.Input)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.Equal(t, test.ExpLongest, longest, test.Name)
+	}
+}
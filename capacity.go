@@ -0,0 +1,240 @@
+package trie
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// EvictionPolicy defines which word is evicted from a trie over its capacity
+type EvictionPolicy int
+
+const (
+	// LRU evicts the least recently used word
+	LRU EvictionPolicy = iota + 1
+	// LFU evicts the least frequently used word, the least recently used of them on a tie
+	LFU
+)
+
+// EvictFunc is called with every word evicted because the trie was over its capacity
+type EvictFunc func(word string, data interface{})
+
+// capacity holds the limits of a trie and the use of its words. Queries count uses, so mu guards the
+// use of the words for readers sharing the trie under a read lock
+type capacity struct {
+	mu         sync.Mutex
+	policy     EvictionPolicy
+	maxEntries int
+	maxBytes   int64
+	bytes      int64
+	onEvict    EvictFunc
+	clock      uint64
+	entries    map[string]*usage
+	queue      usageQueue
+}
+
+// usage is the use of a word, kept in a heap with the next word to evict first
+type usage struct {
+	key   string
+	hits  uint64
+	last  uint64
+	bytes int64
+	index int
+	lfu   bool
+}
+
+// usageQueue is a min-heap of the use of the words
+type usageQueue []*usage
+
+func (q usageQueue) Len() int { return len(q) }
+func (q usageQueue) Less(i, j int) bool {
+	if q[i].lfu && q[i].hits != q[j].hits {
+		return q[i].hits < q[j].hits
+	}
+	return q[i].last < q[j].last
+}
+func (q usageQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+func (q *usageQueue) Push(x interface{}) {
+	u := x.(*usage)
+	u.index = len(*q)
+	*q = append(*q, u)
+}
+func (q *usageQueue) Pop() interface{} {
+	old := *q
+	u := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return u
+}
+
+// WithMaxEntries evicts words by the policy specified once the trie has more than n words
+func WithMaxEntries(n int, policy EvictionPolicy) Option {
+	return func(t *Trie) {
+		c := t.capacity()
+		c.maxEntries = n
+		c.policy = policy
+	}
+}
+
+// WithMaxBytes evicts words by the policy specified once the estimated size of the words is over n
+// bytes. A word is estimated as one node per rune of its key, so words sharing prefixes are
+// overestimated and data is not counted
+func WithMaxBytes(n int64, policy EvictionPolicy) Option {
+	return func(t *Trie) {
+		c := t.capacity()
+		c.maxBytes = n
+		c.policy = policy
+	}
+}
+
+// WithEviction calls fn with every word evicted because the trie was over its capacity. Words
+// evicted during a batch are only passed to fn once the batch succeeds
+func WithEviction(fn EvictFunc) Option {
+	return func(t *Trie) {
+		t.capacity().onEvict = fn
+	}
+}
+
+// capacity returns the capacity of the trie, enabling it on first use
+func (t *Trie) capacity() *capacity {
+	if t.usage == nil {
+		t.usage = &capacity{
+			policy:  LRU,
+			entries: make(map[string]*usage),
+		}
+	}
+	return t.usage
+}
+
+// track starts counting the use of a key which now terminates a word, recording it in a batch
+func (t *Trie) track(key string) {
+	c := t.usage
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+
+	c.clock++
+	u := &usage{
+		key:   key,
+		hits:  1,
+		last:  c.clock,
+		bytes: int64(len([]rune(key))) * nodeHeapBytes(0),
+		lfu:   c.policy == LFU,
+	}
+	c.entries[key] = u
+	c.bytes += u.bytes
+	heap.Push(&c.queue, u)
+
+	t.undo.record(func() { t.untrack(key) })
+}
+
+// untrack stops counting the use of a key which no longer terminates a word, recording it in a
+// batch
+func (t *Trie) untrack(key string) {
+	c := t.usage
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	delete(c.entries, key)
+	c.bytes -= u.bytes
+	heap.Remove(&c.queue, u.index)
+
+	t.undo.record(func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.entries[key] = u
+		c.bytes += u.bytes
+		heap.Push(&c.queue, u)
+	})
+}
+
+// touch counts an access to the word of the key. It is safe for readers to call concurrently
+func (t *Trie) touch(key string) {
+	c := t.usage
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	u, ok := c.entries[key]
+	if !ok {
+		return
+	}
+
+	c.clock++
+	u.hits++
+	u.last = c.clock
+	heap.Fix(&c.queue, u.index)
+}
+
+// evict removes words by the eviction policy while the trie is over its capacity. The word of the
+// key just added is kept, so a new word is never evicted in its own favour
+func (t *Trie) evict(added string) {
+	c := t.usage
+	if c == nil {
+		return
+	}
+
+	for c.over() {
+		victim := c.next(added)
+		if victim == nil {
+			return
+		}
+
+		n := t.nodeAtPrefix([]rune(victim.key))
+		if n == nil {
+			t.untrack(victim.key)
+			continue
+		}
+		word, data := t.spelling(victim.key), n.Data()
+		if err := t.Remove(word); err != nil {
+			t.untrack(victim.key)
+			continue
+		}
+		if c.onEvict != nil {
+			t.afterCommit(func() { c.onEvict(word, data) })
+		}
+	}
+}
+
+// over tells if the words are over the capacity
+func (c *capacity) over() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return (c.maxEntries > 0 && len(c.entries) > c.maxEntries) || (c.maxBytes > 0 && c.bytes > c.maxBytes)
+}
+
+// next gives the word to evict other than the word of the key kept, or nil if there is none
+func (c *capacity) next(keep string) *usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queue) == 0 {
+		return nil
+	}
+	if c.queue[0].key != keep {
+		return c.queue[0]
+	}
+
+	// The next word is one of the children of the root of the heap
+	var next *usage
+	for i := 1; i <= 2 && i < len(c.queue); i++ {
+		if next == nil || c.queue.Less(i, next.index) {
+			next = c.queue[i]
+		}
+	}
+	return next
+}
@@ -0,0 +1,199 @@
+package trie
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEviction(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Opts       []Option
+		Access     func(tr *Trie)
+		ExpWords   []string
+		ExpEvicted []string
+	}{
+		{
+			Name:       "least recently added word is evicted",
+			Opts:       []Option{WithMaxEntries(3, LRU)},
+			Access:     func(tr *Trie) {},
+			ExpWords:   []string{"card", "cart", "dog"},
+			ExpEvicted: []string{"car"},
+		},
+		{
+			Name: "found word is kept",
+			Opts: []Option{WithMaxEntries(3, LRU)},
+			Access: func(tr *Trie) {
+				_, err := tr.Find("car")
+				require.NoError(t, err)
+			},
+			ExpWords:   []string{"car", "cart", "dog"},
+			ExpEvicted: []string{"card"},
+		},
+		{
+			Name: "prefix hits are accesses",
+			Opts: []Option{WithMaxEntries(3, LRU)},
+			Access: func(tr *Trie) {
+				_, err := tr.Find("car")
+				require.NoError(t, err)
+				assert.Equal(t, []string{"card"}, tr.WordsWithPrefix("card"))
+			},
+			ExpWords:   []string{"car", "card", "dog"},
+			ExpEvicted: []string{"cart"},
+		},
+		{
+			Name: "listing every word is not an access",
+			Opts: []Option{WithMaxEntries(3, LRU)},
+			Access: func(tr *Trie) {
+				assert.Len(t, tr.WordsWithPrefix(""), 3)
+			},
+			ExpWords:   []string{"card", "cart", "dog"},
+			ExpEvicted: []string{"car"},
+		},
+		{
+			Name: "peeking at a word is not an access",
+			Opts: []Option{WithMaxEntries(3, LRU)},
+			Access: func(tr *Trie) {
+				_, err := tr.Peek("car")
+				require.NoError(t, err)
+			},
+			ExpWords:   []string{"card", "cart", "dog"},
+			ExpEvicted: []string{"car"},
+		},
+		{
+			Name: "least frequently used word is evicted",
+			Opts: []Option{WithMaxEntries(3, LFU)},
+			Access: func(tr *Trie) {
+				for _, word := range []string{"car", "car", "card", "cart"} {
+					_, err := tr.Find(word)
+					require.NoError(t, err)
+				}
+				_, err := tr.LongestPrefixOf("cards")
+				require.NoError(t, err)
+			},
+			ExpWords:   []string{"car", "card", "dog"},
+			ExpEvicted: []string{"cart"},
+		},
+		{
+			Name:       "estimated bytes are bounded",
+			Opts:       []Option{WithMaxBytes(8*nodeHeapBytes(0), LRU)},
+			Access:     func(tr *Trie) {},
+			ExpWords:   []string{"cart", "dog"},
+			ExpEvicted: []string{"car", "card"},
+		},
+	}
+
+	for _, test := range cases {
+		var evicted []string
+		opts := append(test.Opts, WithEviction(func(word string, _ interface{}) {
+			evicted = append(evicted, word)
+		}))
+		tr := New("capacity", opts...)
+
+		for _, word := range []string{"car", "card", "cart"} {
+			_, err := tr.Add(word, nil)
+			require.NoError(t, err, test.Name)
+		}
+		test.Access(tr)
+		_, err := tr.Add("dog", nil)
+		require.NoError(t, err, test.Name)
+
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		assert.Equal(t, test.ExpEvicted, evicted, test.Name)
+		assert.Len(t, tr.usage.entries, len(test.ExpWords), test.Name)
+	}
+}
+
+func TestConcurrentQueriesCountUses(t *testing.T) {
+	tr := New("capacity", WithMaxEntries(10, LFU))
+	for _, word := range []string{"car", "card", "cart"} {
+		_, err := tr.Add(word, nil)
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				tr.Find("car")
+				tr.WordsWithPrefix("car")
+				tr.LongestPrefixOf("carts")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(1+4*100*2), tr.usage.entries["car"].hits)
+	assert.Equal(t, uint64(1+4*100*2), tr.usage.entries["cart"].hits)
+}
+
+func TestEvictionKeepsNewWord(t *testing.T) {
+	tr := New("capacity", WithMaxEntries(1, LFU))
+	_, err := tr.Add("car", nil)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := tr.Find("car")
+		require.NoError(t, err)
+	}
+
+	_, _, err = tr.Put("dog", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dog"}, tr.Words())
+
+	// The branch of the evicted word is pruned
+	_, ok := tr.Root.Child('c')
+	assert.False(t, ok)
+}
+
+func TestEvictionRollback(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Fail       bool
+		ExpWords   []string
+		ExpEvicted []string
+	}{
+		{
+			Name:     "failed batch restores evicted word without calling back",
+			Fail:     true,
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:       "successful batch calls back once committed",
+			ExpWords:   []string{"cart", "dog"},
+			ExpEvicted: []string{"car"},
+		},
+	}
+
+	for _, test := range cases {
+		var evicted []string
+		tr := New("capacity", WithMaxEntries(2, LRU), WithEviction(func(word string, _ interface{}) {
+			evicted = append(evicted, word)
+		}))
+		for _, word := range []string{"car", "cart"} {
+			_, err := tr.Add(word, nil)
+			require.NoError(t, err, test.Name)
+		}
+
+		err := tr.Batch(func(tx *Tx) error {
+			if _, err := tx.Add("dog", nil); err != nil {
+				return err
+			}
+			assert.Empty(t, evicted, test.Name)
+			if test.Fail {
+				return errors.New("bad line")
+			}
+			return nil
+		})
+		assert.Equal(t, test.Fail, err != nil, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		assert.Equal(t, test.ExpEvicted, evicted, test.Name)
+		assert.Len(t, tr.usage.entries, 2, test.Name)
+		assert.Len(t, tr.usage.queue, 2, test.Name)
+	}
+}
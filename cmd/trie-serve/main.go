@@ -0,0 +1,106 @@
+// Command trie-serve serves tries over HTTP as a JSON autocomplete service.
+//
+// Usage:
+//
+//	trie-serve [-addr addr] [-admin-token token] name=file...
+//
+// Every argument loads a trie under the name used in the URLs. Files ending in .trie are read as
+// written by "trie build". Files ending in .tsv, .csv or .jsonl, optionally followed by .gz, are read
+// as words with data in that format and any other file as newline delimited words. The endpoints are:
+//
+//	GET    /tries
+//	GET    /tries/{name}/find?word=
+//	GET    /tries/{name}/complete?prefix=&limit=
+//	GET    /tries/{name}/top?prefix=&k=
+//	GET    /tries/{name}/fuzzy?word=&distance=&limit=
+//	POST   /admin/tries/{name}/words
+//	DELETE /admin/tries/{name}/words?word=
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	trie "example.com/compact-trie"
+	"example.com/compact-trie/server"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	adminToken := flag.String("admin-token", "", "bearer token for the admin endpoints, which are disabled without one")
+	maxLimit := flag.Int("max-limit", 0, "maximum number of results per query, 0 for the default")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: trie-serve [flags] name=file...")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+
+	tries := map[string]*trie.Trie{}
+	for _, arg := range flag.Args() {
+		name, file, ok := strings.Cut(arg, "=")
+		if !ok || name == "" || file == "" {
+			log.Fatalf("invalid trie %s, expected name=file", arg)
+		}
+		tr, err := loadTrie(name, file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tries[name] = tr
+		log.Printf("loaded trie %s from %s", name, file)
+	}
+
+	srv := server.New(tries, server.Options{
+		AdminToken: *adminToken,
+		MaxLimit:   *maxLimit,
+	})
+	log.Printf("listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, srv))
+}
+
+// loadTrie reads the trie from the file in the format given by its extension
+func loadTrie(name string, file string) (*trie.Trie, error) {
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s: %s", file, err)
+	}
+	defer fh.Close()
+
+	if strings.HasSuffix(file, ".trie") {
+		tr, err := trie.Load(fh)
+		if err != nil {
+			return nil, err
+		}
+		tr.Name = name
+		return tr, nil
+	}
+
+	opts := trie.LoadOptions{Name: name}
+	switch {
+	case strings.HasSuffix(file, ".tsv"), strings.HasSuffix(file, ".tsv.gz"):
+		opts.Format = trie.FormatTSV
+	case strings.HasSuffix(file, ".csv"), strings.HasSuffix(file, ".csv.gz"):
+		opts.Format = trie.FormatCSV
+	case strings.HasSuffix(file, ".jsonl"), strings.HasSuffix(file, ".jsonl.gz"):
+		opts.Format = trie.FormatJSONLines
+	}
+
+	tr, err := trie.NewFromReader(fh, opts)
+	var loadErr *trie.LoadError
+	if errors.As(err, &loadErr) {
+		for _, lineErr := range loadErr.Lines {
+			log.Printf("%s:%s", file, lineErr)
+		}
+		return tr, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s: %s", file, err)
+	}
+	return tr, nil
+}
@@ -0,0 +1,315 @@
+// Command trie builds, queries and inspects tries from the command line.
+//
+// Usage:
+//
+//	trie build [-name name] [-format lines|tsv|csv|jsonl] [-trim] [-comment prefix] [-o file] [file...]
+//	trie find [-t file] word...
+//	trie prefix [-t file] [-limit n] prefix
+//	trie fuzzy [-t file] [-d distance] word
+//	trie stats [-t file]
+//	trie tree [-t file]
+//
+// build reads newline delimited words, or words and data in TSV, CSV or JSON Lines, from plain or
+// gzip compressed files and writes the serialized trie. The other commands read a trie written by
+// build. Input is read from stdin when no file is given, so commands can be piped:
+//
+//	trie build words.txt | trie prefix ca
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	trie "example.com/compact-trie"
+)
+
+const usage = `usage: trie <command> [flags] [args]
+
+commands:
+  build   build a trie from word files and write it serialized
+  find    find words in a trie
+  prefix  list words in a trie beginning with a prefix
+  fuzzy   list words in a trie close to a word
+  stats   print statistics of a trie
+  tree    print a trie as a tree
+`
+
+// errUsage tells that the flags of a command were wrong, which the flag set has already reported
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command in args and returns the exit code
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	cmds := map[string]func([]string, io.Reader, io.Writer, io.Writer) error{
+		"build":  build,
+		"find":   find,
+		"prefix": prefix,
+		"fuzzy":  fuzzy,
+		"stats":  stats,
+		"tree":   tree,
+	}
+	cmd, ok := cmds[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %s\n%s", args[0], usage)
+		return 2
+	}
+
+	if err := cmd(args[1:], stdin, stdout, stderr); err != nil {
+		if err == errUsage {
+			return 2
+		}
+		fmt.Fprintf(stderr, "trie %s: %s\n", args[0], err)
+		return 1
+	}
+
+	return 0
+}
+
+// build reads words from the files given, or stdin, and writes the serialized trie. Lines which
+// cannot be loaded are reported on stderr and skipped
+func build(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs := flag.NewFlagSet("build", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	name := fs.String("name", "", "name of the trie")
+	format := fs.String("format", "lines", "input format: lines, tsv, csv or jsonl")
+	tsv := fs.Bool("tsv", false, "same as -format tsv")
+	trim := fs.Bool("trim", false, "trim white space around every line")
+	comment := fs.String("comment", "", "skip lines starting with this prefix")
+	out := fs.String("o", "", "file to write the trie to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	formats := map[string]trie.Format{
+		"lines": trie.FormatLines,
+		"tsv":   trie.FormatTSV,
+		"csv":   trie.FormatCSV,
+		"jsonl": trie.FormatJSONLines,
+	}
+	if *tsv {
+		*format = "tsv"
+	}
+	f, ok := formats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %s", *format)
+	}
+	opts := trie.LoadOptions{
+		Format:        f,
+		TrimSpace:     *trim,
+		CommentPrefix: *comment,
+	}
+
+	// Every input is added to the same trie so words repeated across files are reported like
+	// repeated lines, and each file is opened on its own so compressed files can be mixed with plain
+	// ones
+	tr := trie.New(*name)
+	if fs.NArg() == 0 {
+		if err := addWords(tr, "stdin", stdin, opts, stderr); err != nil {
+			return err
+		}
+	}
+	for _, file := range fs.Args() {
+		fh, err := os.Open(file)
+		if err != nil {
+			return fmt.Errorf("could not read file %s: %s", file, err)
+		}
+		err = addWords(tr, file, fh, opts, stderr)
+		fh.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if *out == "" {
+		return tr.Save(stdout)
+	}
+	fh, err := os.Create(*out)
+	if err != nil {
+		return fmt.Errorf("could not create file %s: %s", *out, err)
+	}
+	if err := tr.Save(fh); err != nil {
+		fh.Close()
+		return err
+	}
+	return fh.Close()
+}
+
+// addWords adds the words of the input named source to the trie, reporting the lines which could
+// not be added on stderr
+func addWords(tr *trie.Trie, source string, r io.Reader, opts trie.LoadOptions, stderr io.Writer) error {
+	err := tr.AddFromReader(r, opts)
+	var loadErr *trie.LoadError
+	if errors.As(err, &loadErr) {
+		for _, lineErr := range loadErr.Lines {
+			fmt.Fprintf(stderr, "%s:%s\n", source, lineErr)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read %s: %s", source, err)
+	}
+	return nil
+}
+
+// find prints the data of every word given and fails if any of them is not in the trie
+func find(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs, file := trieFlags("find", stderr)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("no word to find")
+	}
+	tr, err := loadTrie(*file, stdin)
+	if err != nil {
+		return err
+	}
+
+	missing := 0
+	for _, word := range fs.Args() {
+		n, err := tr.Find(word)
+		if err != nil {
+			missing++
+			continue
+		}
+		fmt.Fprintf(stdout, "%s\t%v\n", word, n.Data())
+	}
+	if missing > 0 {
+		return fmt.Errorf("%d of %d words not found", missing, fs.NArg())
+	}
+
+	return nil
+}
+
+// prefix prints the words beginning with the prefix given in sorted order
+func prefix(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs, file := trieFlags("prefix", stderr)
+	limit := fs.Int("limit", 0, "maximum number of words to print, 0 for all")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one prefix")
+	}
+	tr, err := loadTrie(*file, stdin)
+	if err != nil {
+		return err
+	}
+
+	words := tr.WordsWithPrefix(fs.Arg(0))
+	sort.Strings(words)
+	if *limit > 0 && len(words) > *limit {
+		words = words[:*limit]
+	}
+	for _, word := range words {
+		fmt.Fprintln(stdout, word)
+	}
+
+	return nil
+}
+
+// fuzzy prints the words close to the word given with their distances, closest first
+func fuzzy(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs, file := trieFlags("fuzzy", stderr)
+	dist := fs.Int("d", 1, "maximum edit distance")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected one word")
+	}
+	tr, err := loadTrie(*file, stdin)
+	if err != nil {
+		return err
+	}
+
+	for _, match := range tr.FuzzyFind(fs.Arg(0), *dist) {
+		fmt.Fprintf(stdout, "%s\t%d\n", match.Word, match.Distance)
+	}
+
+	return nil
+}
+
+// stats prints the statistics of the trie
+func stats(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs, file := trieFlags("stats", stderr)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	tr, err := loadTrie(*file, stdin)
+	if err != nil {
+		return err
+	}
+
+	st := tr.Stats()
+	fmt.Fprintf(stdout, "words:       %d\n", st.Words)
+	fmt.Fprintf(stdout, "nodes:       %d\n", st.Nodes)
+	fmt.Fprintf(stdout, "max depth:   %d\n", st.MaxDepth)
+	fmt.Fprintf(stdout, "avg depth:   %.2f\n", st.AvgDepth)
+	fmt.Fprintf(stdout, "chain nodes: %d\n", st.ChainNodes)
+	fmt.Fprintf(stdout, "heap bytes:  %d\n", st.HeapBytes)
+
+	children := make([]int, 0, len(st.Branching))
+	for c := range st.Branching {
+		children = append(children, c)
+	}
+	sort.Ints(children)
+	fmt.Fprintln(stdout, "branching:")
+	for _, c := range children {
+		fmt.Fprintf(stdout, "  %d children: %d nodes\n", c, st.Branching[c])
+	}
+
+	return nil
+}
+
+// tree prints the trie as a tree
+func tree(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	fs, file := trieFlags("tree", stderr)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	tr, err := loadTrie(*file, stdin)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(stdout, tr.String())
+
+	return nil
+}
+
+// trieFlags creates the flag set of a command reading a serialized trie, reporting flag errors on
+// stderr
+func trieFlags(name string, stderr io.Writer) (*flag.FlagSet, *string) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	file := fs.String("t", "", "file of the trie written by build, stdin if not given")
+	return fs, file
+}
+
+// loadTrie reads a trie written by build from the file, or from stdin if no file is given
+func loadTrie(file string, stdin io.Reader) (*trie.Trie, error) {
+	if file == "" {
+		return trie.Load(stdin)
+	}
+
+	fh, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read file %s: %s", file, err)
+	}
+	defer fh.Close()
+
+	return trie.Load(fh)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	words := filepath.Join(dir, "words.txt")
+	require.Empty(t, os.WriteFile(words, []byte("car\ncart\ncat"), 0o644))
+	data := filepath.Join(dir, "data.tsv")
+	require.Empty(t, os.WriteFile(data, []byte("dog\t4\n"), 0o644))
+	jsonl := filepath.Join(dir, "more.jsonl")
+	require.Empty(t, os.WriteFile(jsonl, []byte("{\"word\":\"cow\",\"data\":5}\n{\"word\":\"cow\"}\n"), 0o644))
+	built := filepath.Join(dir, "words.trie")
+	builtJSON := filepath.Join(dir, "more.trie")
+
+	var cases = []struct {
+		Name      string
+		Args      []string
+		Stdin     string
+		ExpCode   int
+		ExpOut    string
+		ExpErrOut string
+	}{
+		{
+			Name: "build writes the trie to a file",
+			Args: []string{"build", "-name", "words", "-tsv", "-o", built, words, data},
+		},
+		{
+			Name:      "build reports lines which cannot be loaded",
+			Args:      []string{"build", "-format", "jsonl", "-o", builtJSON, jsonl},
+			ExpErrOut: "more.jsonl:line 2: word cow already exists in trie",
+		},
+		{
+			Name:      "build reports words repeated across files",
+			Args:      []string{"build", "-o", filepath.Join(dir, "twice.trie"), words, words},
+			ExpErrOut: "words.txt:line 1: word car already exists in trie",
+		},
+		{
+			Name:   "find prints data of json words",
+			Args:   []string{"find", "-t", builtJSON, "cow"},
+			ExpOut: "cow\t5\n",
+		},
+		{
+			Name:      "build rejects unknown format",
+			Args:      []string{"build", "-format", "xml", words},
+			ExpCode:   1,
+			ExpErrOut: "unknown format xml",
+		},
+		{
+			Name:   "find prints data of found words",
+			Args:   []string{"find", "-t", built, "cart", "dog"},
+			ExpOut: "cart\t\ndog\t4\n",
+		},
+		{
+			Name:      "find fails on missing words",
+			Args:      []string{"find", "-t", built, "cart", "cow"},
+			ExpCode:   1,
+			ExpOut:    "cart\t\n",
+			ExpErrOut: "1 of 2 words not found",
+		},
+		{
+			Name:   "prefix prints sorted words up to the limit",
+			Args:   []string{"prefix", "-t", built, "-limit", "2", "ca"},
+			ExpOut: "car\ncart\n",
+		},
+		{
+			Name:   "fuzzy prints close words",
+			Args:   []string{"fuzzy", "-t", built, "-d", "1", "cot"},
+			ExpOut: "cat\t1\n",
+		},
+		{
+			Name:   "stats prints statistics",
+			Args:   []string{"stats", "-t", built},
+			ExpOut: "words:       4\n",
+		},
+		{
+			Name:   "tree prints the trie",
+			Args:   []string{"tree", "-t", built},
+			ExpOut: "words\n├── c\n",
+		},
+		{
+			Name:      "build reports unknown flags on stderr",
+			Args:      []string{"build", "-x"},
+			ExpCode:   2,
+			ExpErrOut: "flag provided but not defined: -x",
+		},
+		{
+			Name:      "find reports unknown flags on stderr",
+			Args:      []string{"find", "-x", "cow"},
+			ExpCode:   2,
+			ExpErrOut: "flag provided but not defined: -x",
+		},
+		{
+			Name:      "unknown command fails",
+			Args:      []string{"grep"},
+			ExpCode:   2,
+			ExpErrOut: "unknown command grep",
+		},
+	}
+
+	for _, test := range cases {
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+
+		code := run(test.Args, strings.NewReader(test.Stdin), stdout, stderr)
+
+		assert.Equal(t, test.ExpCode, code, test.Name+": "+stderr.String())
+		assert.True(t, strings.HasPrefix(stdout.String(), test.ExpOut), test.Name+": "+stdout.String())
+		assert.Contains(t, stderr.String(), test.ExpErrOut, test.Name)
+	}
+}
+
+func TestRunPipe(t *testing.T) {
+	built := &bytes.Buffer{}
+	code := run([]string{"build"}, strings.NewReader("car\ncart\ndog\n"), built, &bytes.Buffer{})
+	require.Equal(t, 0, code)
+
+	stdout := &bytes.Buffer{}
+	code = run([]string{"prefix", "car"}, built, stdout, &bytes.Buffer{})
+
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "car\ncart\n", stdout.String())
+}
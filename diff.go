@@ -0,0 +1,167 @@
+package trie
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ChangeType defines the kind of change between two tries
+type ChangeType int
+
+const (
+	// Added means the word is only in the second trie
+	Added ChangeType = iota + 1
+	// Removed means the word is only in the first trie
+	Removed
+	// Changed means the word is in both tries with different data
+	Changed
+)
+
+// String gives the name of the change type
+func (ct ChangeType) String() string {
+	switch ct {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return fmt.Sprintf("ChangeType(%d)", int(ct))
+}
+
+// Change defines a single difference between two tries. Old is nil for added words and New is nil
+// for removed words
+type Change struct {
+	Type ChangeType
+	Key  string
+	Old  interface{}
+	New  interface{}
+}
+
+// Patch defines an ordered list of changes which turns one trie into another
+type Patch []Change
+
+// diffOp holds the state of a diff while both tries are walked in parallel
+type diffOp struct {
+	a, b  *Trie
+	path  []rune
+	patch Patch
+}
+
+// Diff returns the changes which turn a into b, ordered by key. Data is compared with
+// reflect.DeepEqual and subtrees found in only one trie are listed without being compared
+func Diff(a, b *Trie) Patch {
+	op := &diffOp{
+		a:     a,
+		b:     b,
+		patch: Patch{},
+	}
+	op.diff(a.root(), b.root())
+	return op.patch
+}
+
+// diff adds the changes between the nodes of a and b in key order. Either node may be nil, in which
+// case every word below the other one is added or removed
+func (op *diffOp) diff(na, nb Node) {
+	aTerm := na != nil && na.IsTerminal() && !op.a.expiredPath(op.path)
+	bTerm := nb != nil && nb.IsTerminal() && !op.b.expiredPath(op.path)
+	key := string(op.path)
+	if aTerm && bTerm {
+		if !reflect.DeepEqual(na.Data(), nb.Data()) {
+			op.patch = append(op.patch, Change{Type: Changed, Key: op.b.spelling(key), Old: na.Data(), New: nb.Data()})
+		}
+	} else if aTerm {
+		op.patch = append(op.patch, Change{Type: Removed, Key: op.a.spelling(key), Old: na.Data()})
+	} else if bTerm {
+		op.patch = append(op.patch, Change{Type: Added, Key: op.b.spelling(key), New: nb.Data()})
+	}
+
+	for _, r := range op.mergedRunes(na, nb) {
+		var ca, cb Node
+		if na != nil {
+			ca, _ = na.Child(r)
+		}
+		if nb != nil {
+			cb, _ = nb.Child(r)
+		}
+		op.path = append(op.path, r)
+		op.diff(ca, cb)
+		op.path = op.path[:len(op.path)-1]
+	}
+}
+
+// mergedRunes returns the runes of the children of either node in ascending order
+func (op *diffOp) mergedRunes(na, nb Node) []rune {
+	if na == nil {
+		return sortedRunes(nb)
+	}
+	if nb == nil {
+		return sortedRunes(na)
+	}
+
+	aRunes, bRunes := sortedRunes(na), sortedRunes(nb)
+	runes := make([]rune, 0, len(aRunes)+len(bRunes))
+	i, j := 0, 0
+	for i < len(aRunes) || j < len(bRunes) {
+		switch {
+		case j == len(bRunes) || (i < len(aRunes) && aRunes[i] < bRunes[j]):
+			runes = append(runes, aRunes[i])
+			i++
+		case i == len(aRunes) || bRunes[j] < aRunes[i]:
+			runes = append(runes, bRunes[j])
+			j++
+		default:
+			runes = append(runes, aRunes[i])
+			i++
+			j++
+		}
+	}
+	return runes
+}
+
+// Apply replays the patch onto the trie in order. The old data of removed and changed words must
+// match the data in the trie, so a patch only applies to the trie it was made from. The patch is
+// applied all or nothing in a batch: if a change does not apply, the changes before it are undone
+// and an error is returned for it. Within a batch, undoing is left to the batch
+func (t *Trie) Apply(patch Patch) error {
+	apply := func() error {
+		for i, change := range patch {
+			if err := t.applyChange(change); err != nil {
+				return fmt.Errorf("could not apply change %d (%s %s): %s", i, change.Type, change.Key, err)
+			}
+		}
+		return nil
+	}
+
+	if t.undo != nil {
+		return apply()
+	}
+	return t.Batch(func(*Tx) error { return apply() })
+}
+
+// applyChange applies a single change to the trie
+func (t *Trie) applyChange(change Change) error {
+	if change.Type == Added {
+		_, err := t.add(change.Key, change.New)
+		return err
+	}
+
+	n, err := t.find(change.Key)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(n.Data(), change.Old) {
+		return fmt.Errorf("data %v does not match %v", n.Data(), change.Old)
+	}
+
+	switch change.Type {
+	case Removed:
+		return t.Remove(change.Key)
+	case Changed:
+		t.setData(n, change.New)
+		t.emit(Change{Type: Changed, Key: change.Key, Old: change.Old, New: change.New})
+		return nil
+	}
+	return fmt.Errorf("unknown change type %s", change.Type)
+}
@@ -0,0 +1,88 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDiffTries(t *testing.T) (*Trie, *Trie) {
+	a := New("a")
+	for word, data := range map[string]interface{}{"car": 1, "cart": 2, "dog": 3, "do": 4} {
+		_, err := a.Add(word, data)
+		require.Empty(t, err)
+	}
+	b := New("b")
+	for word, data := range map[string]interface{}{"car": 1, "cart": 5, "cat": 6, "do": 4, "zebra": 7} {
+		_, err := b.Add(word, data)
+		require.Empty(t, err)
+	}
+	return a, b
+}
+
+func TestDiff(t *testing.T) {
+	a, b := newDiffTries(t)
+
+	expPatch := Patch{
+		{Type: Changed, Key: "cart", Old: 2, New: 5},
+		{Type: Added, Key: "cat", New: 6},
+		{Type: Removed, Key: "dog", Old: 3},
+		{Type: Added, Key: "zebra", New: 7},
+	}
+	assert.Equal(t, expPatch, Diff(a, b))
+	assert.Equal(t, Patch{}, Diff(a, a))
+}
+
+func TestApply(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Patch     string //diff,stale,missing,middle
+		ExpectErr string
+	}{
+		{
+			Name:  "diff applied to the first trie gives the second trie",
+			Patch: "diff",
+		},
+		{
+			Name:      "change with stale old data throws error",
+			Patch:     "stale",
+			ExpectErr: "could not apply change 0 (changed cart): data 2 does not match 9",
+		},
+		{
+			Name:      "removing a missing word throws error",
+			Patch:     "missing",
+			ExpectErr: "could not apply change 0 (removed cow)",
+		},
+		{
+			Name:      "bad change in the middle undoes the changes before it",
+			Patch:     "middle",
+			ExpectErr: "could not apply change 2 (removed cow)",
+		},
+	}
+
+	for _, test := range cases {
+		a, b := newDiffTries(t)
+
+		patch := Diff(a, b)
+		if test.Patch == "stale" {
+			patch = Patch{{Type: Changed, Key: "cart", Old: 9, New: 5}}
+		} else if test.Patch == "missing" {
+			patch = Patch{{Type: Removed, Key: "cow", Old: 1}}
+		} else if test.Patch == "middle" {
+			patch = append(patch[:2:2], append(Patch{{Type: Removed, Key: "cow", Old: 1}}, patch[2:]...)...)
+		}
+
+		err := a.Apply(patch)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			orig, _ := newDiffTries(t)
+			assert.Equal(t, Patch{}, Diff(orig, a), test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.Equal(t, Patch{}, Diff(a, b), test.Name)
+	}
+}
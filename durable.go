@@ -0,0 +1,565 @@
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const snapshotFile = "snapshot"
+const logFile = "wal"
+const defaultCompactAfter = 10000
+const defaultSyncEvery = time.Second
+
+// walHeaderSize is the size of the header before every log record: the length of the record, the
+// CRC-32 checksum of the record and the CRC-32 checksum of these two, so a damaged length is told
+// apart from a record cut short
+const walHeaderSize = 12
+
+// SyncPolicy defines when the log of a DurableTrie is flushed to stable storage
+type SyncPolicy int
+
+const (
+	// SyncAlways flushes the log after every change, so no acknowledged change is lost
+	SyncAlways SyncPolicy = iota
+	// SyncInterval flushes the log in the background every SyncEvery
+	SyncInterval
+	// SyncNever leaves flushing to the operating system
+	SyncNever
+)
+
+// DurableOptions configures a DurableTrie
+type DurableOptions struct {
+	// Options configures the trie. When the directory has a snapshot they are applied after the key
+	// mode and suffix index option saved with it, so options which are not saved, like time to live
+	// options, take effect on every open. Capacity options cannot be used, as evictions are not
+	// logged and replaying the log would evict other words
+	Options []Option
+
+	// Sync is the policy for flushing the log, SyncAlways by default
+	Sync SyncPolicy
+
+	// SyncEvery is the interval of SyncInterval, one second by default
+	SyncEvery time.Duration
+
+	// CompactAfter is the number of log records after which the log is compacted into a new
+	// snapshot, 10000 by default. A negative number only compacts on Compact
+	CompactAfter int
+}
+
+// walOp defines the kind of change of a log record
+type walOp int
+
+const (
+	walPut walOp = iota + 1
+	walRemove
+)
+
+// walFile is the file holding the log
+type walFile interface {
+	io.ReadWriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// errTornRecord tells that the log ends in a record which was only partly written
+var errTornRecord = errors.New("torn record")
+
+// walRecord is the gob encoded form of a change in the log. Records are idempotent, so replaying a
+// log on a snapshot which already has its changes gives the same trie
+type walRecord struct {
+	Op   walOp
+	Word string
+	Data interface{}
+}
+
+// DurableTrie defines a trie whose changes are appended to a write-ahead log in a directory before
+// they are made in memory. Opening the directory again loads the latest snapshot and replays the
+// log. A DurableTrie is safe for concurrent use
+type DurableTrie struct {
+	mu      sync.Mutex
+	dir     string
+	opts    DurableOptions
+	trie    *Trie
+	log     walFile
+	size    int64
+	records int
+	dirty   bool
+	failed  error
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	closed  bool
+}
+
+// OpenDurable opens the durable trie in dir, creating the directory if needed. A new trie is named
+// name; an existing one keeps the name of its snapshot. A partly written record at the end of the
+// log, as left by a crash, is discarded, while a damaged record before it is an error
+func OpenDurable(dir string, name string, opts DurableOptions) (*DurableTrie, error) {
+	if opts.SyncEvery <= 0 {
+		opts.SyncEvery = defaultSyncEvery
+	}
+	if opts.CompactAfter == 0 {
+		opts.CompactAfter = defaultCompactAfter
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create directory %s: %s", dir, err)
+	}
+
+	tr, err := loadSnapshot(filepath.Join(dir, snapshotFile), opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	created := tr == nil
+	if created {
+		tr = New(name, opts.Options...)
+	}
+	if tr.usage != nil {
+		return nil, fmt.Errorf("capacity options cannot be used with a durable trie")
+	}
+
+	fh, err := os.OpenFile(filepath.Join(dir, logFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open log: %s", err)
+	}
+
+	dt := &DurableTrie{
+		dir:  dir,
+		opts: opts,
+		trie: tr,
+		log:  fh,
+		stop: make(chan struct{}),
+	}
+	if err := dt.replay(); err != nil {
+		fh.Close()
+		return nil, err
+	}
+
+	// The first snapshot keeps the name and options of a new trie
+	if created {
+		if err := dt.compact(); err != nil {
+			fh.Close()
+			return nil, err
+		}
+	}
+
+	if opts.Sync == SyncInterval {
+		dt.stopped.Add(1)
+		go dt.syncLoop()
+	}
+
+	return dt, nil
+}
+
+// loadSnapshot loads the trie saved in file with the options given, or returns nil if there is no
+// snapshot yet
+func loadSnapshot(file string, opts []Option) (*Trie, error) {
+	fh, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not open snapshot: %s", err)
+	}
+	defer fh.Close()
+
+	return load(fh, opts...)
+}
+
+// replay applies the records of the log to the trie and truncates a partly written record at its
+// end. Any other damaged record is an error, as the records after it cannot be trusted
+func (dt *DurableTrie) replay() error {
+	data, err := io.ReadAll(dt.log)
+	if err != nil {
+		return fmt.Errorf("could not read log: %s", err)
+	}
+
+	offset := 0
+	for offset < len(data) {
+		rec, size, err := decodeRecord(data[offset:])
+		if errors.Is(err, errTornRecord) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("could not read log record at %d: %s", offset, err)
+		}
+		if err := dt.apply(rec); err != nil {
+			return fmt.Errorf("could not replay log record at %d: %s", offset, err)
+		}
+		offset += size
+		dt.records++
+	}
+
+	if offset < len(data) {
+		if err := dt.log.Truncate(int64(offset)); err != nil {
+			return fmt.Errorf("could not truncate log: %s", err)
+		}
+	}
+	if _, err := dt.log.Seek(int64(offset), io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek log: %s", err)
+	}
+	dt.size = int64(offset)
+
+	return nil
+}
+
+// decodeRecord decodes the record at the start of data and gives its size. errTornRecord is
+// returned if the record is incomplete, or if its checksum does not match and it is the last one.
+// A header whose checksum does not match is only torn if nothing was written after it
+func decodeRecord(data []byte) (walRecord, int, error) {
+	rec := walRecord{}
+	if len(data) < walHeaderSize {
+		return rec, 0, errTornRecord
+	}
+
+	if crc32.ChecksumIEEE(data[0:8]) != binary.BigEndian.Uint32(data[8:12]) {
+		if bytes.Count(data, []byte{0}) == len(data) {
+			return rec, 0, errTornRecord
+		}
+		return rec, 0, fmt.Errorf("header checksum does not match")
+	}
+	size := int(binary.BigEndian.Uint32(data[0:4]))
+	sum := binary.BigEndian.Uint32(data[4:8])
+	if len(data)-walHeaderSize < size {
+		return rec, 0, errTornRecord
+	}
+
+	payload := data[walHeaderSize : walHeaderSize+size]
+	if crc32.ChecksumIEEE(payload) != sum {
+		if len(data) == walHeaderSize+size {
+			return rec, 0, errTornRecord
+		}
+		return rec, 0, fmt.Errorf("checksum does not match")
+	}
+	if err := gob.NewDecoder(bytes.NewReader(payload)).Decode(&rec); err != nil {
+		return rec, 0, fmt.Errorf("could not decode record: %s", err)
+	}
+
+	return rec, walHeaderSize + size, nil
+}
+
+// encodeRecord gives the record with its length and checksum
+func encodeRecord(rec walRecord) ([]byte, error) {
+	buf := bytes.NewBuffer(make([]byte, walHeaderSize))
+	if err := gob.NewEncoder(buf).Encode(rec); err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+	putRecordHeader(data[:walHeaderSize], data[walHeaderSize:])
+
+	return data, nil
+}
+
+// putRecordHeader writes the header of the record payload
+func putRecordHeader(header []byte, payload []byte) {
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+	binary.BigEndian.PutUint32(header[8:12], crc32.ChecksumIEEE(header[0:8]))
+}
+
+// apply makes the change of a record in memory. Removing a missing word is not an error so that
+// records can be replayed more than once
+func (dt *DurableTrie) apply(rec walRecord) error {
+	switch rec.Op {
+	case walPut:
+		_, _, err := dt.trie.Put(rec.Word, rec.Data)
+		return err
+	case walRemove:
+		if err := dt.trie.Remove(rec.Word); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown log operation %d", rec.Op)
+}
+
+// write appends the record to the log, flushes it as the sync policy requires and makes the change
+// in memory. The log is compacted once it has enough records. A record which could not be written
+// is cut from the log again. A record which could not be flushed may still reach the disk, so its
+// change is made and the trie refuses any further change
+func (dt *DurableTrie) write(rec walRecord) error {
+	if err := dt.writable(); err != nil {
+		return err
+	}
+
+	data, err := encodeRecord(rec)
+	if err != nil {
+		return fmt.Errorf("could not encode change of word %s: %s", rec.Word, err)
+	}
+	if _, err := dt.log.Write(data); err != nil {
+		if rerr := dt.rewind(); rerr != nil {
+			dt.failed = rerr
+		}
+		return fmt.Errorf("could not write change of word %s to log: %s", rec.Word, err)
+	}
+	dt.size += int64(len(data))
+	dt.dirty = true
+
+	var syncErr error
+	if dt.opts.Sync == SyncAlways {
+		syncErr = dt.sync()
+	}
+	if err := dt.apply(rec); err != nil {
+		return err
+	}
+	if syncErr != nil {
+		return syncErr
+	}
+
+	dt.records++
+	if dt.opts.CompactAfter > 0 && dt.records >= dt.opts.CompactAfter {
+		return dt.compact()
+	}
+
+	return nil
+}
+
+// writable tells why the trie cannot be changed, if it cannot
+func (dt *DurableTrie) writable() error {
+	if dt.closed {
+		return errorf(ErrClosed, "durable trie is closed")
+	}
+	if dt.failed != nil {
+		return fmt.Errorf("durable trie failed: %s", dt.failed)
+	}
+	return nil
+}
+
+// rewind cuts a partly written record from the end of the log
+func (dt *DurableTrie) rewind() error {
+	if err := dt.log.Truncate(dt.size); err != nil {
+		return fmt.Errorf("could not truncate log: %s", err)
+	}
+	if _, err := dt.log.Seek(dt.size, io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek log: %s", err)
+	}
+	return nil
+}
+
+// sync flushes the log to stable storage if it has unflushed records. A failed flush leaves it
+// unknown which records reached the disk, so the trie refuses any further change
+func (dt *DurableTrie) sync() error {
+	if !dt.dirty {
+		return nil
+	}
+	if err := dt.log.Sync(); err != nil {
+		dt.failed = err
+		return fmt.Errorf("could not sync log: %s", err)
+	}
+	dt.dirty = false
+	return nil
+}
+
+// syncLoop flushes the log every SyncEvery until the trie is closed
+func (dt *DurableTrie) syncLoop() {
+	defer dt.stopped.Done()
+
+	ticker := time.NewTicker(dt.opts.SyncEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-dt.stop:
+			return
+		case <-ticker.C:
+			dt.mu.Lock()
+			_ = dt.sync()
+			dt.mu.Unlock()
+		}
+	}
+}
+
+// View calls fn with the trie in memory for queries while holding the lock of the durable trie. The
+// trie must not be changed by fn nor kept after it returns
+func (dt *DurableTrie) View(fn func(tr *Trie)) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	fn(dt.trie)
+}
+
+// Find checks if the word is in the trie and returns its data
+func (dt *DurableTrie) Find(word string) (interface{}, error) {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	n, err := dt.trie.find(word)
+	if err != nil {
+		return nil, err
+	}
+	dt.trie.touch(dt.trie.key(word))
+
+	return n.Data(), nil
+}
+
+// Add adds the word with its data. An error is returned if the word is already in the trie
+func (dt *DurableTrie) Add(word string, data interface{}) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	if len(word) == 0 || len(dt.trie.key(word)) == 0 {
+		return errorf(ErrEmptyKey, "no string to add")
+	}
+	if _, err := dt.trie.find(word); err == nil {
+		return &ExistsError{Word: word}
+	}
+
+	return dt.write(walRecord{Op: walPut, Word: word, Data: data})
+}
+
+// Put adds the word with its data, replacing the data if the word is already in the trie
+func (dt *DurableTrie) Put(word string, data interface{}) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	if len(word) == 0 || len(dt.trie.key(word)) == 0 {
+		return errorf(ErrEmptyKey, "no string to put")
+	}
+
+	return dt.write(walRecord{Op: walPut, Word: word, Data: data})
+}
+
+// Remove removes the word. An error is returned if the word is not in the trie
+func (dt *DurableTrie) Remove(word string) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	if _, err := dt.trie.find(word); err != nil {
+		return fmt.Errorf("could not find word %s in trie: %w", word, err)
+	}
+
+	return dt.write(walRecord{Op: walRemove, Word: word})
+}
+
+// Update calls fn with the data of the word and logs and stores the result as Trie.Update does
+func (dt *DurableTrie) Update(word string, fn UpdateFunc) error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	if len(word) == 0 || len(dt.trie.key(word)) == 0 {
+		return errorf(ErrEmptyKey, "no string to update")
+	}
+
+	var old interface{}
+	n, err := dt.trie.find(word)
+	exists := err == nil
+	if exists {
+		old = n.Data()
+	}
+
+	data, keep := fn(old, exists)
+	switch {
+	case keep:
+		return dt.write(walRecord{Op: walPut, Word: word, Data: data})
+	case exists:
+		return dt.write(walRecord{Op: walRemove, Word: word})
+	}
+	return nil
+}
+
+// Sync flushes the log to stable storage whatever the sync policy
+func (dt *DurableTrie) Sync() error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	return dt.sync()
+}
+
+// Compact saves the trie in a new snapshot and empties the log
+func (dt *DurableTrie) Compact() error {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+
+	if err := dt.writable(); err != nil {
+		return err
+	}
+	return dt.compact()
+}
+
+// compact replaces the snapshot atomically and then truncates the log. A crash in between leaves
+// the records in the log, which replay to the same trie
+func (dt *DurableTrie) compact() error {
+	tmp := filepath.Join(dt.dir, snapshotFile+".tmp")
+	fh, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("could not create snapshot: %s", err)
+	}
+	if err := dt.trie.Save(fh); err != nil {
+		fh.Close()
+		return err
+	}
+	if err := fh.Sync(); err != nil {
+		fh.Close()
+		return fmt.Errorf("could not sync snapshot: %s", err)
+	}
+	if err := fh.Close(); err != nil {
+		return fmt.Errorf("could not close snapshot: %s", err)
+	}
+
+	if err := os.Rename(tmp, filepath.Join(dt.dir, snapshotFile)); err != nil {
+		return fmt.Errorf("could not replace snapshot: %s", err)
+	}
+	if err := syncDir(dt.dir); err != nil {
+		return err
+	}
+
+	if err := dt.log.Truncate(0); err != nil {
+		return fmt.Errorf("could not truncate log: %s", err)
+	}
+	if _, err := dt.log.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek log: %s", err)
+	}
+	dt.size = 0
+	dt.records = 0
+	dt.dirty = true
+
+	return dt.sync()
+}
+
+// syncDir flushes the entries of the directory so a renamed file survives a crash
+func syncDir(dir string) error {
+	fh, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("could not open directory %s: %s", dir, err)
+	}
+	defer fh.Close()
+
+	if err := fh.Sync(); err != nil {
+		return fmt.Errorf("could not sync directory %s: %s", dir, err)
+	}
+	return nil
+}
+
+// Close flushes and closes the log. The trie cannot be changed afterwards
+func (dt *DurableTrie) Close() error {
+	dt.mu.Lock()
+	if dt.closed {
+		dt.mu.Unlock()
+		return nil
+	}
+	dt.closed = true
+	close(dt.stop)
+	dt.mu.Unlock()
+
+	dt.stopped.Wait()
+
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	if err := dt.sync(); err != nil {
+		dt.log.Close()
+		return err
+	}
+	if err := dt.log.Close(); err != nil {
+		return fmt.Errorf("could not close log: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,312 @@
+package trie
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// durableState gives the words of the durable trie with their data
+func durableState(t *testing.T, dt *DurableTrie) map[string]interface{} {
+	state := map[string]interface{}{}
+	var words []string
+	dt.View(func(tr *Trie) {
+		words = tr.Words()
+	})
+	for _, word := range words {
+		data, err := dt.Find(word)
+		require.NoError(t, err)
+		state[word] = data
+	}
+	return state
+}
+
+func TestDurableTrie(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Opts     DurableOptions
+		Crash    func(t *testing.T, dir string)
+		ExpState map[string]interface{}
+	}{
+		{
+			Name:     "changes are replayed from the log",
+			ExpState: map[string]interface{}{"Car": 3, "cat": "two", "dog": 5},
+		},
+		{
+			Name:     "changes are loaded from compacted snapshots",
+			Opts:     DurableOptions{CompactAfter: 2, Options: []Option{WithCaseFolding()}},
+			ExpState: map[string]interface{}{"Car": 3, "cat": "two", "dog": 5},
+		},
+		{
+			Name: "partly written record is discarded",
+			Opts: DurableOptions{Sync: SyncNever, CompactAfter: -1},
+			Crash: func(t *testing.T, dir string) {
+				file := filepath.Join(dir, logFile)
+				info, err := os.Stat(file)
+				require.NoError(t, err)
+				require.NoError(t, os.Truncate(file, info.Size()-3))
+			},
+			ExpState: map[string]interface{}{"Car": 3, "cat": "two"},
+		},
+		{
+			Name: "record with a wrong checksum is discarded",
+			Opts: DurableOptions{Sync: SyncInterval, SyncEvery: time.Millisecond, CompactAfter: -1},
+			Crash: func(t *testing.T, dir string) {
+				file := filepath.Join(dir, logFile)
+				data, err := os.ReadFile(file)
+				require.NoError(t, err)
+				data[len(data)-1] ^= 0xff
+				require.NoError(t, os.WriteFile(file, data, 0o644))
+			},
+			ExpState: map[string]interface{}{"Car": 3, "cat": "two"},
+		},
+		{
+			Name: "zeros after the last record are discarded",
+			Opts: DurableOptions{CompactAfter: -1},
+			Crash: func(t *testing.T, dir string) {
+				file := filepath.Join(dir, logFile)
+				data, err := os.ReadFile(file)
+				require.NoError(t, err)
+				data = append(data, make([]byte, 40)...)
+				require.NoError(t, os.WriteFile(file, data, 0o644))
+			},
+			ExpState: map[string]interface{}{"Car": 3, "cat": "two", "dog": 5},
+		},
+	}
+
+	for _, test := range cases {
+		dir := t.TempDir()
+		dt, err := OpenDurable(dir, "durable", test.Opts)
+		require.NoError(t, err, test.Name)
+
+		require.NoError(t, dt.Add("Car", 1), test.Name)
+		require.NoError(t, dt.Add("cat", "two"), test.Name)
+		assert.ErrorIs(t, dt.Add("Car", 2), ErrExists, test.Name)
+		require.NoError(t, dt.Put("Car", 3), test.Name)
+		require.NoError(t, dt.Add("cart", 4), test.Name)
+		require.NoError(t, dt.Remove("cart"), test.Name)
+		assert.ErrorIs(t, dt.Remove("cart"), ErrNotFound, test.Name)
+		require.NoError(t, dt.Update("dog", func(old interface{}, exists bool) (interface{}, bool) {
+			return 5, !exists
+		}), test.Name)
+		require.NoError(t, dt.Close(), test.Name)
+
+		if test.Crash != nil {
+			test.Crash(t, dir)
+		}
+
+		dt, err = OpenDurable(dir, "other", DurableOptions{})
+		require.NoError(t, err, test.Name)
+		dt.View(func(tr *Trie) {
+			assert.Equal(t, "durable", tr.Name, test.Name)
+		})
+		assert.Equal(t, test.ExpState, durableState(t, dt), test.Name)
+
+		// Records after a discarded one are written where it was
+		require.NoError(t, dt.Put("emu", 6), test.Name)
+		require.NoError(t, dt.Close(), test.Name)
+		dt, err = OpenDurable(dir, "", DurableOptions{})
+		require.NoError(t, err, test.Name)
+		data, err := dt.Find("emu")
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, 6, data, test.Name)
+		require.NoError(t, dt.Close(), test.Name)
+	}
+}
+
+func TestDurableTrieCompact(t *testing.T) {
+	dir := t.TempDir()
+	dt, err := OpenDurable(dir, "durable", DurableOptions{CompactAfter: -1})
+	require.NoError(t, err)
+
+	require.NoError(t, dt.Add("car", 1))
+	require.NoError(t, dt.Add("cat", 2))
+	stale, err := os.ReadFile(filepath.Join(dir, logFile))
+	require.NoError(t, err)
+	require.NotEmpty(t, stale)
+
+	require.NoError(t, dt.Compact())
+	info, err := os.Stat(filepath.Join(dir, logFile))
+	require.NoError(t, err)
+	assert.Zero(t, info.Size())
+
+	require.NoError(t, dt.Remove("car"))
+	require.NoError(t, dt.Close())
+	assert.EqualError(t, dt.Add("dog", 3), "durable trie is closed")
+
+	// A crash before the log was truncated replays records already in the snapshot
+	log, err := os.ReadFile(filepath.Join(dir, logFile))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, logFile), append(stale, log...), 0o644))
+
+	dt, err = OpenDurable(dir, "durable", DurableOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cat": 2}, durableState(t, dt))
+	require.NoError(t, dt.Close())
+}
+
+func TestDurableTrieDamagedLog(t *testing.T) {
+	var cases = []struct {
+		Name   string
+		Damage func(data []byte) []byte
+	}{
+		{
+			Name: "wrong checksum before the last record",
+			Damage: func(data []byte) []byte {
+				data[walHeaderSize] ^= 0xff
+				return data
+			},
+		},
+		{
+			Name: "record which cannot be decoded",
+			Damage: func(data []byte) []byte {
+				rec := []byte{0xff, 0xff, 0xff}
+				header := make([]byte, walHeaderSize)
+				putRecordHeader(header, rec)
+				return append(append(data, header...), rec...)
+			},
+		},
+		{
+			Name: "length of a record before the last one running past the end",
+			Damage: func(data []byte) []byte {
+				binary.BigEndian.PutUint32(data[0:4], uint32(len(data)))
+				return data
+			},
+		},
+	}
+
+	for _, test := range cases {
+		dir := t.TempDir()
+		dt, err := OpenDurable(dir, "durable", DurableOptions{CompactAfter: -1})
+		require.NoError(t, err, test.Name)
+		require.NoError(t, dt.Add("car", 1), test.Name)
+		require.NoError(t, dt.Add("cat", 2), test.Name)
+		require.NoError(t, dt.Close(), test.Name)
+
+		file := filepath.Join(dir, logFile)
+		data, err := os.ReadFile(file)
+		require.NoError(t, err, test.Name)
+		data = test.Damage(data)
+		require.NoError(t, os.WriteFile(file, data, 0o644), test.Name)
+
+		_, err = OpenDurable(dir, "durable", DurableOptions{})
+		assert.ErrorContains(t, err, "could not read log record at", test.Name)
+
+		// The damaged log is left for inspection
+		damaged, err := os.ReadFile(file)
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, data, damaged, test.Name)
+	}
+}
+
+// failingFile is a log file whose writes or syncs fail once told to
+type failingFile struct {
+	walFile
+	failWrite bool
+	failSync  bool
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if f.failWrite {
+		// Half of the record reaches the file before the failure
+		n, _ := f.walFile.Write(p[:len(p)/2])
+		return n, errors.New("disk full")
+	}
+	return f.walFile.Write(p)
+}
+
+func (f *failingFile) Sync() error {
+	if f.failSync {
+		return errors.New("io error")
+	}
+	return f.walFile.Sync()
+}
+
+func TestDurableTrieWriteFailure(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		FailWrite bool
+		FailSync  bool
+		ExpErr    string
+		ExpFound  bool
+		ExpLater  string
+		ExpState  map[string]interface{}
+	}{
+		{
+			Name:      "failed write is cut from the log",
+			FailWrite: true,
+			ExpErr:    "could not write change of word cat to log: disk full",
+			ExpState:  map[string]interface{}{"car": 1, "dog": 3},
+		},
+		{
+			Name:     "failed sync keeps the change and refuses later ones",
+			FailSync: true,
+			ExpErr:   "could not sync log: io error",
+			ExpFound: true,
+			ExpLater: "durable trie failed: io error",
+			ExpState: map[string]interface{}{"car": 1, "cat": 2},
+		},
+	}
+
+	for _, test := range cases {
+		dir := t.TempDir()
+		dt, err := OpenDurable(dir, "durable", DurableOptions{CompactAfter: -1})
+		require.NoError(t, err, test.Name)
+		require.NoError(t, dt.Add("car", 1), test.Name)
+
+		f := &failingFile{walFile: dt.log, failWrite: test.FailWrite, failSync: test.FailSync}
+		dt.log = f
+		assert.EqualError(t, dt.Add("cat", 2), test.ExpErr, test.Name)
+		_, err = dt.Find("cat")
+		assert.Equal(t, test.ExpFound, err == nil, test.Name)
+
+		f.failWrite, f.failSync = false, false
+		err = dt.Add("dog", 3)
+		if test.ExpLater != "" {
+			assert.EqualError(t, err, test.ExpLater, test.Name)
+		} else {
+			assert.NoError(t, err, test.Name)
+		}
+		require.NoError(t, dt.Close(), test.Name)
+
+		dt, err = OpenDurable(dir, "durable", DurableOptions{})
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, test.ExpState, durableState(t, dt), test.Name)
+		require.NoError(t, dt.Close(), test.Name)
+	}
+}
+
+func TestDurableTrieReopenOptions(t *testing.T) {
+	dir := t.TempDir()
+	dt, err := OpenDurable(dir, "durable", DurableOptions{Options: []Option{WithCaseFolding()}})
+	require.NoError(t, err)
+	for i, word := range []string{"Car", "Cart", "Cat"} {
+		require.NoError(t, dt.Add(word, i))
+	}
+	require.NoError(t, dt.Close())
+
+	// Evictions are not logged, so replaying the log could evict other words
+	_, err = OpenDurable(dir, "durable", DurableOptions{Options: []Option{WithMaxEntries(3, LRU)}})
+	assert.EqualError(t, err, "capacity options cannot be used with a durable trie")
+
+	now := time.Unix(0, 0)
+	dt, err = OpenDurable(dir, "durable", DurableOptions{Options: []Option{
+		WithClock(func() time.Time { return now }),
+	}})
+	require.NoError(t, err)
+	dt.View(func(tr *Trie) {
+		assert.Equal(t, now, tr.ttl.now())
+	})
+
+	// The key mode saved with the snapshot is kept
+	data, err := dt.Find("CART")
+	require.NoError(t, err)
+	assert.Equal(t, 1, data)
+	require.NoError(t, dt.Close())
+}
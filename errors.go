@@ -0,0 +1,94 @@
+package trie
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is matched by the errors of words which are not in the trie
+	ErrNotFound = errors.New("not found")
+
+	// ErrExists is matched by the errors of adding a word which is already in the trie
+	ErrExists = errors.New("word already exists in trie")
+
+	// ErrEmptyKey is matched by the errors of empty words, or of words whose key is empty
+	ErrEmptyKey = errors.New("empty key")
+
+	// ErrNotTerminal is matched by the errors of words whose path is in the trie but which are not
+	// terminated. It also matches ErrNotFound
+	ErrNotTerminal = errors.New("not terminal")
+
+	// ErrClosed is matched by the errors of using a finished transaction or builder, or a closed
+	// durable trie
+	ErrClosed = errors.New("closed")
+)
+
+// ExistsError is the error of adding a word which is already in the trie. It matches ErrExists
+type ExistsError struct {
+	// Word is the word added
+	Word string
+}
+
+// Error tells which word already exists
+func (e *ExistsError) Error() string {
+	return fmt.Sprintf("word %s already exists in trie", e.Word)
+}
+
+// Is matches ErrExists
+func (e *ExistsError) Is(target error) bool {
+	return target == ErrExists
+}
+
+// NotFoundError is the error of a key which is not in the trie. It matches ErrNotFound and, if the
+// whole path of the key is in the trie, ErrNotTerminal
+type NotFoundError struct {
+	// Key is the key looked up, after the key mode of the trie is applied
+	Key string
+
+	// Prefix is the longest prefix of the key in the trie
+	Prefix string
+
+	// Pos is the position in runes of the first rune of the key not in the trie. It is the length
+	// of the key when the path exists but is not terminated
+	Pos int
+}
+
+// Error gives the longest prefix found or tells that the path is not terminated
+func (e *NotFoundError) Error() string {
+	if e.NotTerminal() {
+		return fmt.Sprintf("string %s not found but exists as a non-terminated path", e.Key)
+	}
+	return fmt.Sprintf("string %s not found, longest prefix found: %s", e.Key, e.Prefix)
+}
+
+// NotTerminal tells if the whole path of the key is in the trie
+func (e *NotFoundError) NotTerminal() bool {
+	return e.Pos == len([]rune(e.Key))
+}
+
+// Is matches ErrNotFound and, if the path is not terminated, ErrNotTerminal
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound || (target == ErrNotTerminal && e.NotTerminal())
+}
+
+// sentinelError is an error with its own message which matches a sentinel error
+type sentinelError struct {
+	msg string
+	err error
+}
+
+// errorf formats an error message which matches err with errors.Is
+func errorf(err error, format string, args ...interface{}) error {
+	return &sentinelError{msg: fmt.Sprintf(format, args...), err: err}
+}
+
+// Error gives the message of the error
+func (e *sentinelError) Error() string {
+	return e.msg
+}
+
+// Unwrap gives the sentinel error matched
+func (e *sentinelError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,234 @@
+package trie
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors(t *testing.T) {
+	tr := New("errors")
+	_, err := tr.Add("card", 1)
+	require.NoError(t, err)
+	_, err = tr.Add("cart", 2)
+	require.NoError(t, err)
+
+	var cases = []struct {
+		Name      string
+		Op        string
+		Word      string
+		ExpIs     []error
+		ExpIsNot  []error
+		ExpPrefix string
+		ExpPos    int
+		ExpectErr string
+	}{
+		{
+			Name:      "missing word gives the longest prefix",
+			Op:        "find",
+			Word:      "cast",
+			ExpIs:     []error{ErrNotFound},
+			ExpIsNot:  []error{ErrNotTerminal, ErrExists},
+			ExpPrefix: "ca",
+			ExpPos:    2,
+			ExpectErr: "word cast not found: string cast not found, longest prefix found: ca",
+		},
+		{
+			Name:      "missing first rune has an empty prefix",
+			Op:        "find",
+			Word:      "dog",
+			ExpIs:     []error{ErrNotFound},
+			ExpPrefix: "",
+			ExpPos:    0,
+		},
+		{
+			Name:      "non terminated path is not terminal",
+			Op:        "find",
+			Word:      "car",
+			ExpIs:     []error{ErrNotFound, ErrNotTerminal},
+			ExpPrefix: "car",
+			ExpPos:    3,
+			ExpectErr: "word car not found: string car not found but exists as a non-terminated path",
+		},
+		{
+			Name:      "removing a missing word is not found",
+			Op:        "remove",
+			Word:      "cars",
+			ExpIs:     []error{ErrNotFound},
+			ExpPrefix: "car",
+			ExpPos:    3,
+		},
+		{
+			Name:      "adding a word again exists",
+			Op:        "add",
+			Word:      "card",
+			ExpIs:     []error{ErrExists},
+			ExpIsNot:  []error{ErrNotFound},
+			ExpectErr: "word card already exists in trie",
+		},
+		{
+			Name:      "adding an empty word is an empty key",
+			Op:        "add",
+			ExpIs:     []error{ErrEmptyKey},
+			ExpectErr: "no string to add",
+		},
+		{
+			Name:      "finding an empty word is an empty key",
+			Op:        "find",
+			ExpIs:     []error{ErrEmptyKey},
+			ExpectErr: "no string to find",
+		},
+	}
+
+	for _, test := range cases {
+		var err error
+		switch test.Op {
+		case "find":
+			_, err = tr.Find(test.Word)
+		case "add":
+			_, err = tr.Add(test.Word, nil)
+		case "remove":
+			err = tr.Remove(test.Word)
+		}
+
+		require.Error(t, err, test.Name)
+		for _, target := range test.ExpIs {
+			assert.ErrorIs(t, err, target, test.Name)
+		}
+		for _, target := range test.ExpIsNot {
+			assert.NotErrorIs(t, err, target, test.Name)
+		}
+		if test.ExpectErr != "" {
+			assert.EqualError(t, err, test.ExpectErr, test.Name)
+		}
+
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			assert.Equal(t, test.ExpPrefix, notFound.Prefix, test.Name)
+			assert.Equal(t, test.ExpPos, notFound.Pos, test.Name)
+		} else {
+			assert.NotErrorIs(t, err, ErrNotFound, test.Name)
+		}
+	}
+}
+
+func TestErrorsOfOtherQueries(t *testing.T) {
+	tr := New("errors", WithSuffixIndex())
+	_, err := tr.Add("card", nil)
+	require.NoError(t, err)
+
+	_, err = tr.LongestPrefixOf("dog")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.EqualError(t, err, "no prefix of dog found in trie")
+
+	_, err = tr.LongestSuffixOf("dog")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ft := tr.Freeze()
+	_, err = ft.Find("car")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = ft.Find("")
+	assert.ErrorIs(t, err, ErrEmptyKey)
+}
+
+func TestErrorsOfOtherTries(t *testing.T) {
+	bt := NewBytes("bytes")
+	_, err := bt.Add([]byte{0x01}, nil)
+	require.NoError(t, err)
+
+	pt := NewPathTrie()
+	require.NoError(t, pt.Add("/users/:id", nil))
+
+	var tx *Tx
+	require.NoError(t, New("tx").Batch(func(t *Tx) error {
+		tx = t
+		return nil
+	}))
+
+	b := NewBuilder("builder")
+	b.Trie()
+
+	dt, err := OpenDurable(t.TempDir(), "durable", DurableOptions{})
+	require.NoError(t, err)
+	require.NoError(t, dt.Add("car", nil))
+
+	var cases = []struct {
+		Name      string
+		Err       error
+		ExpIs     error
+		ExpectErr string
+	}{
+		{
+			Name:  "missing bytes key is not found",
+			Err:   bt.Remove([]byte{0x02}),
+			ExpIs: ErrNotFound,
+		},
+		{
+			Name:      "empty bytes key is an empty key",
+			Err:       func() error { _, err := bt.Add(nil, nil); return err }(),
+			ExpIs:     ErrEmptyKey,
+			ExpectErr: "no key to add",
+		},
+		{
+			Name:  "missing route is not found",
+			Err:   NewRouteTable().Delete(netip.MustParsePrefix("10.0.0.0/8")),
+			ExpIs: ErrNotFound,
+		},
+		{
+			Name:  "missing pattern is not found",
+			Err:   pt.Remove("/users"),
+			ExpIs: ErrNotFound,
+		},
+		{
+			Name:  "repeated pattern exists",
+			Err:   pt.Add("/users/:id", nil),
+			ExpIs: ErrExists,
+		},
+		{
+			Name:  "empty pattern is an empty key",
+			Err:   pt.Add("", nil),
+			ExpIs: ErrEmptyKey,
+		},
+		{
+			Name:      "finished transaction is closed",
+			Err:       tx.Remove("car"),
+			ExpIs:     ErrClosed,
+			ExpectErr: "transaction is already finished",
+		},
+		{
+			Name:  "finished builder is closed",
+			Err:   b.Add("car", nil),
+			ExpIs: ErrClosed,
+		},
+		{
+			Name:      "repeated durable word exists",
+			Err:       dt.Add("car", nil),
+			ExpIs:     ErrExists,
+			ExpectErr: "word car already exists in trie",
+		},
+		{
+			Name:  "closed durable trie is closed",
+			Err:   func() error { require.NoError(t, dt.Close()); return dt.Put("car", nil) }(),
+			ExpIs: ErrClosed,
+		},
+	}
+
+	for _, test := range cases {
+		require.Error(t, test.Err, test.Name)
+		assert.ErrorIs(t, test.Err, test.ExpIs, test.Name)
+		if test.ExpectErr != "" {
+			assert.EqualError(t, test.Err, test.ExpectErr, test.Name)
+		}
+	}
+
+	tr := New("exists")
+	_, err = tr.Add("car", nil)
+	require.NoError(t, err)
+	_, err = tr.Add("car", nil)
+	var exists *ExistsError
+	require.ErrorAs(t, err, &exists)
+	assert.Equal(t, "car", exists.Word)
+}
@@ -0,0 +1,109 @@
+package trie
+
+import (
+	"strings"
+	"sync"
+)
+
+// Observer is called with every change of a trie once it is made, Key being the word as it was
+// added. The changes can be applied to a replica of the trie with Apply. Changes made during a batch
+// are delivered when the batch succeeds and not at all if it fails
+type Observer func(e Change)
+
+// eventHub holds the observers of a trie in the order they were registered
+type eventHub struct {
+	mu        sync.Mutex
+	nextID    int
+	observers []observerEntry
+}
+
+// observerEntry identifies an observer so it can be removed
+type observerEntry struct {
+	id int
+	fn Observer
+}
+
+// Observe registers fn to be called after every Add, Remove and data update of the trie, in the
+// goroutine making the change. The returned function removes the observer
+func (t *Trie) Observe(fn Observer) (cancel func()) {
+	hub := t.events
+
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	id := hub.nextID
+	hub.nextID++
+	hub.observers = append(hub.observers, observerEntry{id: id, fn: fn})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hub.mu.Lock()
+			defer hub.mu.Unlock()
+			for i, entry := range hub.observers {
+				if entry.id == id {
+					hub.observers = append(hub.observers[:i:i], hub.observers[i+1:]...)
+					break
+				}
+			}
+		})
+	}
+}
+
+// Subscribe returns a channel receiving the events of the words beginning with prefix, compared
+// by key. The channel has room for buffer events, after which changes to the trie wait for the
+// subscriber. The returned function ends the subscription and closes the channel; it may be called
+// from any goroutine
+func (t *Trie) Subscribe(prefix string, buffer int) (<-chan Change, func()) {
+	ch := make(chan Change, buffer)
+	done := make(chan struct{})
+	// mu guards closed, which stops a change delivered after the observer was removed from being
+	// sent on the closed channel
+	var mu sync.Mutex
+	closed := false
+
+	keyPrefix := t.key(prefix)
+	stop := t.Observe(func(e Change) {
+		if !strings.HasPrefix(t.key(e.Key), keyPrefix) {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case <-done:
+		case ch <- e:
+		}
+	})
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			stop()
+			// Releases a change waiting for room in the channel before closing it
+			close(done)
+			mu.Lock()
+			defer mu.Unlock()
+			closed = true
+			close(ch)
+		})
+	}
+}
+
+// emit delivers a change to the observers, or holds it until the end of the batch in progress
+func (t *Trie) emit(e Change) {
+	if t.undo != nil {
+		t.undo.events = append(t.undo.events, e)
+		return
+	}
+
+	t.events.mu.Lock()
+	observers := append([]observerEntry(nil), t.events.observers...)
+	t.events.mu.Unlock()
+
+	for _, entry := range observers {
+		entry.fn(e)
+	}
+}
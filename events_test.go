@@ -0,0 +1,230 @@
+package trie
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Ops       func(tr *Trie) error
+		ExpEvents []Change
+	}{
+		{
+			Name: "add and remove are observed",
+			Ops: func(tr *Trie) error {
+				if _, err := tr.Add("cart", 2); err != nil {
+					return err
+				}
+				return tr.Remove("car")
+			},
+			ExpEvents: []Change{
+				{Type: Added, Key: "cart", New: 2},
+				{Type: Removed, Key: "car", Old: 1},
+			},
+		},
+		{
+			Name: "failed changes are not observed",
+			Ops: func(tr *Trie) error {
+				_, err := tr.Add("car", 3)
+				assert.Error(t, err)
+				assert.Error(t, tr.Remove("cat"))
+				return nil
+			},
+			ExpEvents: nil,
+		},
+		{
+			Name: "put, update and apply change data",
+			Ops: func(tr *Trie) error {
+				if _, _, err := tr.Put("car", 3); err != nil {
+					return err
+				}
+				if _, _, err := tr.Put("cab", 4); err != nil {
+					return err
+				}
+				if _, _, err := tr.AddIfAbsent("car", 5); err != nil {
+					return err
+				}
+				if _, err := tr.Update("cab", func(interface{}, bool) (interface{}, bool) { return nil, false }); err != nil {
+					return err
+				}
+				return tr.Apply(Patch{{Type: Changed, Key: "car", Old: 3, New: 6}})
+			},
+			ExpEvents: []Change{
+				{Type: Changed, Key: "car", Old: 1, New: 3},
+				{Type: Added, Key: "cab", New: 4},
+				{Type: Removed, Key: "cab", Old: 4},
+				{Type: Changed, Key: "car", Old: 3, New: 6},
+			},
+		},
+		{
+			Name: "successful batch is observed at the end",
+			Ops: func(tr *Trie) error {
+				return tr.Batch(func(tx *Tx) error {
+					if _, err := tx.Add("cart", 2); err != nil {
+						return err
+					}
+					return tx.Remove("cart")
+				})
+			},
+			ExpEvents: []Change{
+				{Type: Added, Key: "cart", New: 2},
+				{Type: Removed, Key: "cart", Old: 2},
+			},
+		},
+		{
+			Name: "failed batch is not observed",
+			Ops: func(tr *Trie) error {
+				err := tr.Batch(func(tx *Tx) error {
+					if _, err := tx.Add("cart", 2); err != nil {
+						return err
+					}
+					return errors.New("bad line")
+				})
+				assert.Error(t, err)
+				return nil
+			},
+			ExpEvents: nil,
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("events")
+		_, err := tr.Add("car", 1)
+		require.NoError(t, err)
+
+		var events []Change
+		cancel := tr.Observe(func(e Change) {
+			events = append(events, e)
+		})
+
+		require.NoError(t, test.Ops(tr), test.Name)
+		assert.Equal(t, test.ExpEvents, events, test.Name)
+
+		cancel()
+		_, err = tr.Add("other", nil)
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, test.ExpEvents, events, test.Name)
+	}
+}
+
+func TestObserveSpelling(t *testing.T) {
+	tr := New("events", WithCaseFolding())
+
+	var events []Change
+	tr.Observe(func(e Change) {
+		events = append(events, e)
+	})
+
+	_, err := tr.Add("Car", 1)
+	require.NoError(t, err)
+	require.NoError(t, tr.Remove("CAR"))
+
+	assert.Equal(t, []Change{
+		{Type: Added, Key: "Car", New: 1},
+		{Type: Removed, Key: "Car", Old: 1},
+	}, events)
+}
+
+func TestSubscribe(t *testing.T) {
+	tr := New("events", WithCaseFolding())
+	ch, cancel := tr.Subscribe("CA", 1)
+
+	var received []Change
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for e := range ch {
+			received = append(received, e)
+		}
+	}()
+
+	for _, word := range []string{"car", "dog", "Cat", "cab"} {
+		_, err := tr.Add(word, nil)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tr.Remove("dog"))
+	require.NoError(t, tr.Remove("car"))
+
+	cancel()
+	wg.Wait()
+
+	assert.Equal(t, []Change{
+		{Type: Added, Key: "car"},
+		{Type: Added, Key: "Cat"},
+		{Type: Added, Key: "cab"},
+		{Type: Removed, Key: "car"},
+	}, received)
+
+	// Changes after the end of the subscription are not sent
+	_, err := tr.Add("cart", nil)
+	require.NoError(t, err)
+	cancel()
+}
+
+func TestSubscribeCancelWhileFull(t *testing.T) {
+	tr := New("events")
+	_, cancel := tr.Subscribe("", 0)
+
+	added := make(chan struct{})
+	go func() {
+		defer close(added)
+		_, err := tr.Add("car", nil)
+		assert.NoError(t, err)
+	}()
+
+	// The change waiting for the subscriber is released by the end of the subscription
+	cancel()
+	<-added
+}
+
+func TestObserveWhileChanging(t *testing.T) {
+	tr := New("events")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_, err := tr.Add(fmt.Sprintf("word%d", i), i)
+			require.NoError(t, err)
+		}
+	}()
+
+	var mu sync.Mutex
+	added := 0
+	cancel := tr.Observe(func(e Change) {
+		mu.Lock()
+		defer mu.Unlock()
+		added++
+	})
+	<-done
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.LessOrEqual(t, added, 100)
+}
+
+func TestSubscribeCancelDuringChange(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		tr := New("events")
+		var cancel func()
+		// The earlier observer ends the subscription after the change was handed to every observer
+		tr.Observe(func(Change) {
+			cancel()
+		})
+		ch, stop := tr.Subscribe("", 1)
+		cancel = stop
+
+		_, err := tr.Add("car", nil)
+		require.NoError(t, err)
+		_, ok := <-ch
+		assert.False(t, ok)
+	}
+}
@@ -0,0 +1,155 @@
+package trie
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ExportOptions configures the DOT and Mermaid exports of a trie
+type ExportOptions struct {
+	// MaxDepth limits the export to nodes at most this deep. Zero exports every node
+	MaxDepth int
+	// HighlightTerminal draws terminating nodes differently from the other nodes
+	HighlightTerminal bool
+	// ShowData adds the data of terminating nodes to their labels
+	ShowData bool
+	// HighlightWord highlights the nodes and edges on the path of the word, as far as it exists
+	HighlightWord string
+}
+
+// exportNode holds what the exporters need to draw a node
+type exportNode struct {
+	id          int
+	parent      int
+	label       string
+	edge        string
+	terminal    bool
+	highlighted bool
+}
+
+// WriteDOT writes the trie as a Graphviz DOT digraph
+func (t *Trie) WriteDOT(w io.Writer, opts ExportOptions) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("digraph %s {\n", dotQuote(t.Name))
+	ew.printf("\tnode [shape=circle];\n")
+	for _, en := range t.exportNodes(opts) {
+		attrs := []string{"label=" + dotQuote(en.label)}
+		if opts.HighlightTerminal && en.terminal {
+			attrs = append(attrs, "shape=doublecircle")
+		}
+		if en.highlighted {
+			attrs = append(attrs, "color=red", "penwidth=2")
+		}
+		if en.id == 0 {
+			attrs[0] = "label=" + dotQuote(t.Name)
+			attrs = append(attrs, "shape=box")
+		}
+		ew.printf("\tn%d [%s];\n", en.id, strings.Join(attrs, ", "))
+
+		if en.id == 0 {
+			continue
+		}
+		edgeAttrs := []string{"label=" + dotQuote(en.edge)}
+		if en.highlighted {
+			edgeAttrs = append(edgeAttrs, "color=red", "penwidth=2")
+		}
+		ew.printf("\tn%d -> n%d [%s];\n", en.parent, en.id, strings.Join(edgeAttrs, ", "))
+	}
+	ew.printf("}\n")
+
+	return ew.err
+}
+
+// WriteMermaid writes the trie as a Mermaid flowchart
+func (t *Trie) WriteMermaid(w io.Writer, opts ExportOptions) error {
+	ew := &errWriter{w: w}
+
+	ew.printf("flowchart TD\n")
+	edge := 0
+	highlightedEdges := []string{}
+	for _, en := range t.exportNodes(opts) {
+		if en.id == 0 {
+			ew.printf("\tn0[%s]\n", mermaidQuote(t.Name))
+		} else {
+			shape := "((%s))"
+			if opts.HighlightTerminal && en.terminal {
+				shape = "(((%s)))"
+			}
+			ew.printf("\tn%d -- %s --> n%d"+shape+"\n", en.parent, mermaidQuote(en.edge), en.id, mermaidQuote(en.label))
+			if en.highlighted {
+				highlightedEdges = append(highlightedEdges, fmt.Sprint(edge))
+			}
+			edge++
+		}
+		if en.highlighted {
+			ew.printf("\tclass n%d highlighted\n", en.id)
+		}
+	}
+	ew.printf("\tclassDef highlighted stroke:red,stroke-width:2px\n")
+	if len(highlightedEdges) > 0 {
+		ew.printf("\tlinkStyle %s stroke:red,stroke-width:2px\n", strings.Join(highlightedEdges, ","))
+	}
+
+	return ew.err
+}
+
+// exportNodes walks the trie in sorted pre-order and gives the nodes to draw, root first
+func (t *Trie) exportNodes(opts ExportOptions) []exportNode {
+	highlight := []rune(t.key(opts.HighlightWord))
+	ids := map[Node]int{}
+	nodes := []exportNode{}
+
+	t.walk(func(path []rune, n Node) WalkAction {
+		// A word whose time to live has passed is drawn like a path without a word
+		en := exportNode{
+			id:       len(nodes),
+			terminal: n.IsTerminal() && !t.expiredPath(path),
+		}
+		ids[n] = en.id
+
+		if !n.IsRoot() {
+			en.parent = ids[n.Parent()]
+			en.edge = string(n.Value())
+			en.label = string(n.Value())
+			if opts.ShowData && en.terminal && n.Data() != nil {
+				en.label = fmt.Sprintf("%c: %v", n.Value(), n.Data())
+			}
+		}
+		en.highlighted = len(highlight) > 0 && len(path) <= len(highlight) && string(highlight[:len(path)]) == string(path)
+
+		nodes = append(nodes, en)
+
+		if opts.MaxDepth > 0 && len(path) >= opts.MaxDepth {
+			return SkipChildren
+		}
+		return Continue
+	}, SortedChildren())
+
+	return nodes
+}
+
+// dotQuote gives the string as a quoted DOT identifier
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// mermaidQuote gives the string as quoted Mermaid text
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(s) + `"`
+}
+
+// errWriter writes formatted output until the first error, which it keeps
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+// printf writes the formatted output unless an earlier write failed
+func (ew *errWriter) printf(format string, args ...interface{}) {
+	if ew.err != nil {
+		return
+	}
+	_, ew.err = fmt.Fprintf(ew.w, format, args...)
+}
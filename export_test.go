@@ -0,0 +1,132 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingWriter struct{}
+
+func (fw failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func newExportTrie(t *testing.T) *Trie {
+	tr := New("test")
+	for word, data := range map[string]interface{}{"ab": 1, "b": nil} {
+		_, err := tr.Add(word, data)
+		require.Empty(t, err)
+	}
+	return tr
+}
+
+func TestWriteDOT(t *testing.T) {
+	var cases = []struct {
+		Name   string
+		Opts   ExportOptions
+		ExpDOT string
+	}{
+		{
+			Name: "plain trie is exported",
+			ExpDOT: `digraph "test" {
+	node [shape=circle];
+	n0 [label="test", shape=box];
+	n1 [label="a"];
+	n0 -> n1 [label="a"];
+	n2 [label="b"];
+	n1 -> n2 [label="b"];
+	n3 [label="b"];
+	n0 -> n3 [label="b"];
+}
+`,
+		},
+		{
+			Name: "terminals, data and word path are highlighted",
+			Opts: ExportOptions{HighlightTerminal: true, ShowData: true, HighlightWord: "ab"},
+			ExpDOT: `digraph "test" {
+	node [shape=circle];
+	n0 [label="test", color=red, penwidth=2, shape=box];
+	n1 [label="a", color=red, penwidth=2];
+	n0 -> n1 [label="a", color=red, penwidth=2];
+	n2 [label="b: 1", shape=doublecircle, color=red, penwidth=2];
+	n1 -> n2 [label="b", color=red, penwidth=2];
+	n3 [label="b", shape=doublecircle];
+	n0 -> n3 [label="b"];
+}
+`,
+		},
+		{
+			Name: "depth limit cuts deeper nodes",
+			Opts: ExportOptions{MaxDepth: 1},
+			ExpDOT: `digraph "test" {
+	node [shape=circle];
+	n0 [label="test", shape=box];
+	n1 [label="a"];
+	n0 -> n1 [label="a"];
+	n2 [label="b"];
+	n0 -> n2 [label="b"];
+}
+`,
+		},
+	}
+
+	tr := newExportTrie(t)
+
+	for _, test := range cases {
+		buf := &bytes.Buffer{}
+		err := tr.WriteDOT(buf, test.Opts)
+		require.Empty(t, err, test.Name)
+		assert.Equal(t, test.ExpDOT, buf.String(), test.Name)
+	}
+
+	err := tr.WriteDOT(failingWriter{}, ExportOptions{})
+	require.NotEmpty(t, err)
+	assert.Contains(t, err.Error(), "disk full")
+}
+
+func TestWriteMermaid(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Opts       ExportOptions
+		ExpMermaid string
+	}{
+		{
+			Name: "plain trie is exported",
+			ExpMermaid: `flowchart TD
+	n0["test"]
+	n0 -- "a" --> n1(("a"))
+	n1 -- "b" --> n2(("b"))
+	n0 -- "b" --> n3(("b"))
+	classDef highlighted stroke:red,stroke-width:2px
+`,
+		},
+		{
+			Name: "terminals, data and word path are highlighted",
+			Opts: ExportOptions{HighlightTerminal: true, ShowData: true, HighlightWord: "ab"},
+			ExpMermaid: `flowchart TD
+	n0["test"]
+	class n0 highlighted
+	n0 -- "a" --> n1(("a"))
+	class n1 highlighted
+	n1 -- "b" --> n2((("b: 1")))
+	class n2 highlighted
+	n0 -- "b" --> n3((("b")))
+	classDef highlighted stroke:red,stroke-width:2px
+	linkStyle 0,1 stroke:red,stroke-width:2px
+`,
+		},
+	}
+
+	tr := newExportTrie(t)
+
+	for _, test := range cases {
+		buf := &bytes.Buffer{}
+		err := tr.WriteMermaid(buf, test.Opts)
+		require.Empty(t, err, test.Name)
+		assert.Equal(t, test.ExpMermaid, buf.String(), test.Name)
+	}
+}
@@ -0,0 +1,166 @@
+package trie
+
+import (
+	"sort"
+)
+
+// FrozenTrie defines a read-only trie whose nodes are laid out breadth first in flat slices. The
+// children of a node are contiguous and sorted, so a trie of millions of words is a handful of
+// allocations and a child is found by binary search
+type FrozenTrie struct {
+	Name string
+
+	// labels holds the rune of every node, with the root at index 0
+	labels []rune
+	// first holds the index of the first child of every node, and first[i+1] ends the children of
+	// node i. It has one more entry than there are nodes
+	first []int32
+	// term flags the terminating nodes and data holds their data
+	term []bool
+	data []interface{}
+
+	words     int
+	keyMode   *keyMode
+	spellings map[string]string
+}
+
+// Freeze returns the trie in its frozen form. Later changes to the trie are not reflected in it
+func (t *Trie) Freeze() *FrozenTrie {
+	ft := &FrozenTrie{
+		Name:    t.Name,
+		keyMode: t.keyMode,
+		labels:  []rune{0},
+		term:    []bool{t.root().IsTerminal()},
+		data:    []interface{}{t.root().Data()},
+	}
+
+	if t.spellings != nil {
+		ft.spellings = make(map[string]string, len(t.spellings))
+		for key, word := range t.spellings {
+			ft.spellings[key] = word
+		}
+	}
+
+	queue := []Node{t.root()}
+	for i := 0; i < len(queue); i++ {
+		ft.first = append(ft.first, int32(len(ft.labels)))
+		for _, cNode := range queue[i].SortedChildren() {
+			ft.labels = append(ft.labels, cNode.Value())
+			term := cNode.IsTerminal() && (t.ttl == nil || !t.expired(cNode.Path()))
+			ft.term = append(ft.term, term)
+			ft.data = append(ft.data, cNode.Data())
+			if term {
+				ft.words++
+			}
+			queue = append(queue, cNode)
+		}
+	}
+	ft.first = append(ft.first, int32(len(ft.labels)))
+
+	return ft
+}
+
+// Len gives the number of words in the trie
+func (ft *FrozenTrie) Len() int {
+	return ft.words
+}
+
+// Find checks if the trie has the word and returns its data
+func (ft *FrozenTrie) Find(word string) (interface{}, error) {
+	key := ft.key(word)
+	if len(key) == 0 {
+		return nil, errorf(ErrEmptyKey, "no string to find")
+	}
+
+	i, ok := ft.nodeAtPrefix([]rune(key))
+	if !ok || !ft.term[i] {
+		return nil, errorf(ErrNotFound, "word %s not found", word)
+	}
+
+	return ft.data[i], nil
+}
+
+// Words returns an array of the words in the trie in ascending order of their keys
+func (ft *FrozenTrie) Words() []string {
+	return ft.WordsWithPrefix("")
+}
+
+// WordsWithPrefix returns an array of the words in the trie that begin with prefix in ascending
+// order of their keys
+func (ft *FrozenTrie) WordsWithPrefix(prefix string) []string {
+	words := []string{}
+
+	key := []rune(ft.key(prefix))
+	if i, ok := ft.nodeAtPrefix(key); ok {
+		ft.wordsAtNode(i, key, &words)
+	}
+
+	return words
+}
+
+// LongestPrefixOf returns the longest word in the trie that is a prefix of s. An error is returned
+// if no word in the trie is a prefix of s
+func (ft *FrozenTrie) LongestPrefixOf(s string) (string, error) {
+	runes := []rune(ft.key(s))
+
+	i, length := int32(0), 0
+	for pos, r := range runes {
+		var ok bool
+		if i, ok = ft.child(i, r); !ok {
+			break
+		}
+		if ft.term[i] {
+			length = pos + 1
+		}
+	}
+	if length == 0 {
+		return "", errorf(ErrNotFound, "no prefix of %s found in trie", s)
+	}
+
+	return ft.spelling(string(runes[:length])), nil
+}
+
+// child gives the index of the child of node i for the rune
+func (ft *FrozenTrie) child(i int32, r rune) (int32, bool) {
+	lo, hi := ft.first[i], ft.first[i+1]
+	c := lo + int32(sort.Search(int(hi-lo), func(j int) bool { return ft.labels[lo+int32(j)] >= r }))
+	if c < hi && ft.labels[c] == r {
+		return c, true
+	}
+	return 0, false
+}
+
+// nodeAtPrefix gives the index of the node where the runes end
+func (ft *FrozenTrie) nodeAtPrefix(runes []rune) (int32, bool) {
+	i := int32(0)
+	for _, r := range runes {
+		var ok bool
+		if i, ok = ft.child(i, r); !ok {
+			return 0, false
+		}
+	}
+	return i, true
+}
+
+// wordsAtNode adds the words at and below node i in order
+func (ft *FrozenTrie) wordsAtNode(i int32, path []rune, words *[]string) {
+	if ft.term[i] {
+		*words = append(*words, ft.spelling(string(path)))
+	}
+	for c := ft.first[i]; c < ft.first[i+1]; c++ {
+		ft.wordsAtNode(c, append(path, ft.labels[c]), words)
+	}
+}
+
+// key gives the key of the word under the key mode the trie was frozen with
+func (ft *FrozenTrie) key(word string) string {
+	return ft.keyMode.key(word)
+}
+
+// spelling gives the word as it was added for the key
+func (ft *FrozenTrie) spelling(key string) string {
+	if word, ok := ft.spellings[key]; ok {
+		return word
+	}
+	return key
+}
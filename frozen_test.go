@@ -0,0 +1,112 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrozenTrie(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Fun       string //find,prefix,longest
+		Input     string
+		ExpData   interface{}
+		ExpWords  []string
+		ExpWord   string
+		ExpectErr string
+	}{
+		{
+			Name:    "word is found with data",
+			Fun:     "find",
+			Input:   "cart",
+			ExpData: 2,
+		},
+		{
+			Name:      "non terminating path is not found",
+			Fun:       "find",
+			Input:     "ca",
+			ExpectErr: "word ca not found",
+		},
+		{
+			Name:      "empty word throws error",
+			Fun:       "find",
+			Input:     "",
+			ExpectErr: "no string to find",
+		},
+		{
+			Name:     "words with prefix are sorted",
+			Fun:      "prefix",
+			Input:    "ca",
+			ExpWords: []string{"car", "card", "cart"},
+		},
+		{
+			Name:     "all words are sorted",
+			Fun:      "prefix",
+			Input:    "",
+			ExpWords: []string{"car", "card", "cart", "dog"},
+		},
+		{
+			Name:    "longest prefix is found",
+			Fun:     "longest",
+			Input:   "cartoon",
+			ExpWord: "cart",
+		},
+		{
+			Name:      "no prefix throws error",
+			Fun:       "longest",
+			Input:     "cow",
+			ExpectErr: "no prefix of cow found",
+		},
+	}
+
+	tr := New("test")
+	for word, data := range map[string]interface{}{"car": 1, "cart": 2, "card": 3, "dog": 4} {
+		_, err := tr.Add(word, data)
+		require.Empty(t, err)
+	}
+	ft := tr.Freeze()
+
+	// The frozen trie does not follow later changes
+	require.Empty(t, tr.Remove("dog"))
+	assert.Equal(t, 4, ft.Len())
+	assert.Equal(t, "test", ft.Name)
+
+	for _, test := range cases {
+		var data interface{}
+		var words []string
+		var word string
+		var err error
+		if test.Fun == "find" {
+			data, err = ft.Find(test.Input)
+		} else if test.Fun == "prefix" {
+			words = ft.WordsWithPrefix(test.Input)
+		} else if test.Fun == "longest" {
+			word, err = ft.LongestPrefixOf(test.Input)
+		}
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		require.Empty(t, err, test.Name)
+		assert.Equal(t, test.ExpData, data, test.Name)
+		assert.Equal(t, test.ExpWords, words, test.Name)
+		assert.Equal(t, test.ExpWord, word, test.Name)
+	}
+}
+
+func TestBuilderFreeze(t *testing.T) {
+	b := NewBuilder("test", WithCaseFolding())
+	for i, word := range []string{"Alpha", "beta", "Gamma"} {
+		require.Empty(t, b.Add(word, i))
+	}
+	ft := b.Freeze()
+
+	data, err := ft.Find("BETA")
+	require.Empty(t, err)
+	assert.Equal(t, 1, data)
+	assert.Equal(t, []string{"Alpha", "beta", "Gamma"}, ft.Words())
+}
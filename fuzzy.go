@@ -0,0 +1,93 @@
+package trie
+
+import (
+	"sort"
+)
+
+// FuzzyMatch defines a word found by a fuzzy search along with its edit distance from the query
+type FuzzyMatch struct {
+	Word     string
+	Distance int
+	Data     interface{}
+}
+
+// fuzzyOp holds the state of a fuzzy search while the trie is walked
+type fuzzyOp struct {
+	t        *Trie
+	target   []rune
+	maxDist  int
+	matches  []FuzzyMatch
+	children childStack
+}
+
+// FuzzyFind returns the words within maxDist insertions, deletions or substitutions of word, ordered
+// by distance and then by word. Branches which cannot come within maxDist are not descended
+func (t *Trie) FuzzyFind(word string, maxDist int) []FuzzyMatch {
+	op := &fuzzyOp{
+		t:       t,
+		target:  []rune(t.key(word)),
+		maxDist: maxDist,
+		matches: []FuzzyMatch{},
+	}
+	if maxDist < 0 {
+		return op.matches
+	}
+
+	// The row holds the distances between the runes of the path so far and every prefix of target
+	row := make([]int, len(op.target)+1)
+	for i := range row {
+		row[i] = i
+	}
+	op.descend(t.root(), []rune{}, row)
+
+	matches := op.matches
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Distance != matches[j].Distance {
+			return matches[i].Distance < matches[j].Distance
+		}
+		return matches[i].Word < matches[j].Word
+	})
+
+	return matches
+}
+
+// descend visits the children of the node, whose path and distance row are given
+func (op *fuzzyOp) descend(n Node, path []rune, row []int) {
+	start, end := op.children.push(n, false)
+	for i := start; i < end; i++ {
+		cNode := op.children[i]
+		op.visit(cNode, append(path, cNode.Value()), row)
+	}
+	op.children.pop(start)
+}
+
+// visit computes the distance row of the node from the row of its parent, adds the node if it is a
+// close enough word and descends while any distance in the row is within maxDist
+func (op *fuzzyOp) visit(n Node, path []rune, prevRow []int) {
+	r := path[len(path)-1]
+	row := make([]int, len(prevRow))
+	row[0] = prevRow[0] + 1
+	minDist := row[0]
+
+	for i := 1; i < len(row); i++ {
+		cost := 1
+		if op.target[i-1] == r {
+			cost = 0
+		}
+		row[i] = min(row[i-1]+1, prevRow[i]+1, prevRow[i-1]+cost)
+		minDist = min(minDist, row[i])
+	}
+
+	if dist := row[len(row)-1]; dist <= op.maxDist && n.IsTerminal() && !op.t.expiredPath(path) {
+		op.matches = append(op.matches, FuzzyMatch{
+			Word:     op.t.spelling(string(path)),
+			Distance: dist,
+			Data:     n.Data(),
+		})
+	}
+
+	if minDist > op.maxDist {
+		return
+	}
+	op.descend(n, path, row)
+}
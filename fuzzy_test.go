@@ -0,0 +1,63 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyFind(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Word       string
+		MaxDist    int
+		ExpMatches []FuzzyMatch
+	}{
+		{
+			Name:    "exact word has distance zero",
+			Word:    "cart",
+			MaxDist: 0,
+			ExpMatches: []FuzzyMatch{
+				{Word: "cart", Distance: 0, Data: 2},
+			},
+		},
+		{
+			Name:    "words within distance are ordered by distance and word",
+			Word:    "cart",
+			MaxDist: 1,
+			ExpMatches: []FuzzyMatch{
+				{Word: "cart", Distance: 0, Data: 2},
+				{Word: "car", Distance: 1, Data: 1},
+				{Word: "card", Distance: 1, Data: 3},
+				{Word: "carts", Distance: 1, Data: 4},
+			},
+		},
+		{
+			Name:    "transposed runes take two edits",
+			Word:    "cra",
+			MaxDist: 2,
+			ExpMatches: []FuzzyMatch{
+				{Word: "car", Distance: 2, Data: 1},
+				{Word: "card", Distance: 2, Data: 3},
+				{Word: "cart", Distance: 2, Data: 2},
+			},
+		},
+		{
+			Name:       "negative distance matches nothing",
+			Word:       "cart",
+			MaxDist:    -1,
+			ExpMatches: []FuzzyMatch{},
+		},
+	}
+
+	tr := New("test")
+	for word, data := range map[string]interface{}{"car": 1, "cart": 2, "card": 3, "carts": 4, "dog": 5} {
+		_, err := tr.Add(word, data)
+		require.Empty(t, err)
+	}
+
+	for _, test := range cases {
+		assert.Equal(t, test.ExpMatches, tr.FuzzyFind(test.Word, test.MaxDist), test.Name)
+	}
+}
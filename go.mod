@@ -6,6 +6,7 @@ require (
 	github.com/disiqueira/gotree/v3 v3.0.2
 	github.com/google/go-cmp v0.7.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/text v0.21.0
 )
 
 require (
@@ -0,0 +1,279 @@
+package trie
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format defines the layout of the input read by NewFromReader
+type Format int
+
+const (
+	// FormatLines reads one word per line and gives every word empty data
+	FormatLines Format = iota
+	// FormatTSV reads tab separated columns, one of which is the word and another its data. Lines
+	// without the data column give the word empty data
+	FormatTSV
+	// FormatCSV reads comma separated columns like FormatTSV, with quoting as in encoding/csv.
+	// Quoted fields cannot span lines
+	FormatCSV
+	// FormatJSONLines reads a JSON object per line with the word and its data in two of its fields
+	FormatJSONLines
+)
+
+const defaultKeyField = "word"
+const defaultDataField = "data"
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// LoadOptions configures how NewFromReader builds a trie
+type LoadOptions struct {
+	// Name is the name of the trie
+	Name string
+	// Format is the layout of the input
+	Format Format
+	// Options are passed to New when the trie is created
+	Options []Option
+
+	// KeyColumn is the column of the word in TSV and CSV input counting from 1. The first column
+	// is used if it is zero and it cannot be negative
+	KeyColumn int
+	// DataColumn is the column of the data in TSV and CSV input counting from 1. The second column
+	// is used if it is zero and no column if it is negative
+	DataColumn int
+	// Delimiter separates the columns of CSV input. A comma is used if it is zero
+	Delimiter rune
+	// KeyField and DataField are the fields of the word and its data in JSON Lines input. "word"
+	// and "data" are used if they are empty
+	KeyField  string
+	DataField string
+
+	// TrimSpace removes leading and trailing white space from every line
+	TrimSpace bool
+	// CommentPrefix skips the lines starting with it, after trimming, if it is not empty
+	CommentPrefix string
+
+	// Workers builds the trie with this many goroutines when it is more than 1, which cannot be
+	// combined with capacity options. See NewFromFileParallel
+	Workers int
+}
+
+// LineError describes a line of the input which could not be loaded
+type LineError struct {
+	Line int
+	Err  error
+}
+
+// Error gives the line number with the reason it could not be loaded
+func (le LineError) Error() string {
+	return fmt.Sprintf("line %d: %s", le.Line, le.Err)
+}
+
+// LoadError reports the lines which could not be loaded. NewFromReader returns it along with a trie
+// holding every other line
+type LoadError struct {
+	Lines []LineError
+}
+
+// Error summarizes the lines which could not be loaded
+func (le *LoadError) Error() string {
+	return fmt.Sprintf("%d lines could not be loaded, first %s", len(le.Lines), le.Lines[0])
+}
+
+// NewFromReader creates a trie from the words read from r. Gzip compressed input is decompressed
+// transparently and lines may be of any length. Empty lines are skipped. Lines which cannot be
+// parsed or hold a word already in the trie are reported in a *LoadError, which is returned along
+// with the trie of the other lines. Any other error means the input could not be read
+func NewFromReader(r io.Reader, opts LoadOptions) (*Trie, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	br, closeInput, err := openInput(r)
+	if err != nil {
+		return nil, err
+	}
+	defer closeInput()
+
+	if opts.Workers > 1 {
+		return opts.loadParallel(br)
+	}
+
+	tr := New(opts.Name, opts.Options...)
+	if err := opts.load(tr, br); err != nil {
+		var loadErr *LoadError
+		if errors.As(err, &loadErr) {
+			return tr, err
+		}
+		return nil, err
+	}
+
+	return tr, nil
+}
+
+// AddFromReader adds the words read from r to the trie like NewFromReader, so words loaded from
+// several inputs are checked against each other. Lines which cannot be parsed or hold a word already
+// in the trie are reported in a *LoadError and the other lines are still added. The Name, Options
+// and Workers of the options are not used
+func (t *Trie) AddFromReader(r io.Reader, opts LoadOptions) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	br, closeInput, err := openInput(r)
+	if err != nil {
+		return err
+	}
+	defer closeInput()
+
+	return opts.load(t, br)
+}
+
+// validate checks the options which would fail on every line
+func (opts LoadOptions) validate() error {
+	if opts.KeyColumn < 0 {
+		return fmt.Errorf("key column %d is not a column", opts.KeyColumn)
+	}
+	return nil
+}
+
+// openInput reads r through a buffer, decompressing it if it is gzip compressed. The returned
+// function releases the decompressor
+func openInput(r io.Reader) (*bufio.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err == nil && string(magic) == string(gzipMagic) {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not read gzip input: %s", err)
+		}
+		return bufio.NewReader(gz), func() { gz.Close() }, nil
+	}
+	return br, func() {}, nil
+}
+
+// load adds the words of every line of the input to the trie, returning a *LoadError for the lines
+// which could not be added
+func (opts LoadOptions) load(tr *Trie, br *bufio.Reader) error {
+	loadErr := &LoadError{}
+	err := opts.readLines(br, loadErr, func(lineNo int, word string, data interface{}) {
+		if _, err := tr.add(word, data); err != nil {
+			loadErr.Lines = append(loadErr.Lines, LineError{Line: lineNo, Err: err})
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(loadErr.Lines) > 0 {
+		return loadErr
+	}
+
+	return nil
+}
+
+// readLines parses every line of the input and calls fn with the word and data of the line. Lines
+// which cannot be parsed are added to loadErr
+func (opts LoadOptions) readLines(br *bufio.Reader, loadErr *LoadError, fn func(lineNo int, word string, data interface{})) error {
+	for lineNo := 1; ; lineNo++ {
+		line, readErr := br.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("could not read line %d: %s", lineNo, readErr)
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if opts.TrimSpace {
+			line = strings.TrimSpace(line)
+		}
+		skip := len(line) == 0 || (opts.CommentPrefix != "" && strings.HasPrefix(line, opts.CommentPrefix))
+
+		if !skip {
+			word, data, err := opts.parseLine(line)
+			if err != nil {
+				loadErr.Lines = append(loadErr.Lines, LineError{Line: lineNo, Err: err})
+			} else {
+				fn(lineNo, word, data)
+			}
+		}
+
+		if readErr == io.EOF {
+			return nil
+		}
+	}
+}
+
+// parseLine gives the word and data of the line in the format of the options
+func (opts LoadOptions) parseLine(line string) (string, interface{}, error) {
+	switch opts.Format {
+	case FormatLines:
+		return line, "", nil
+	case FormatTSV:
+		return opts.columns(strings.Split(line, "\t"))
+	case FormatCSV:
+		cr := csv.NewReader(strings.NewReader(line))
+		cr.FieldsPerRecord = -1
+		if opts.Delimiter != 0 {
+			cr.Comma = opts.Delimiter
+		}
+		fields, err := cr.Read()
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid CSV: %s", err)
+		}
+		return opts.columns(fields)
+	case FormatJSONLines:
+		return opts.fields(line)
+	}
+	return "", nil, fmt.Errorf("unknown format %d", opts.Format)
+}
+
+// columns gives the word and data from the columns of a TSV or CSV line
+func (opts LoadOptions) columns(cols []string) (string, interface{}, error) {
+	keyCol := opts.KeyColumn
+	if keyCol == 0 {
+		keyCol = 1
+	}
+	dataCol := opts.DataColumn
+	if dataCol == 0 {
+		dataCol = 2
+	}
+
+	if keyCol > len(cols) {
+		return "", nil, fmt.Errorf("no column %d for the word", keyCol)
+	}
+	word := cols[keyCol-1]
+
+	var data interface{} = ""
+	if dataCol > 0 && dataCol <= len(cols) {
+		data = cols[dataCol-1]
+	}
+
+	return word, data, nil
+}
+
+// fields gives the word and data from the fields of a JSON Lines object
+func (opts LoadOptions) fields(line string) (string, interface{}, error) {
+	keyField := opts.KeyField
+	if keyField == "" {
+		keyField = defaultKeyField
+	}
+	dataField := opts.DataField
+	if dataField == "" {
+		dataField = defaultDataField
+	}
+
+	obj := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %s", err)
+	}
+
+	word, ok := obj[keyField].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("no string field %s for the word", keyField)
+	}
+
+	return word, obj[dataField], nil
+}
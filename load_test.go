@@ -0,0 +1,172 @@
+package trie
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingReader struct{}
+
+func (fr failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestNewFromReader(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Input      string
+		Gzip       bool
+		Opts       LoadOptions
+		ExpData    map[string]interface{}
+		ExpLineErr []string
+		ExpectErr  string
+	}{
+		{
+			Name:    "lines are loaded with empty data",
+			Input:   "ab\n\nb\r\nc",
+			ExpData: map[string]interface{}{"ab": "", "b": "", "c": ""},
+		},
+		{
+			Name:    "white space is trimmed and comments are skipped",
+			Input:   "  ab \n# comment\n\t# indented comment\nb\n",
+			Opts:    LoadOptions{TrimSpace: true, CommentPrefix: "#"},
+			ExpData: map[string]interface{}{"ab": "", "b": ""},
+		},
+		{
+			Name:    "tsv lines are loaded with data",
+			Input:   "ab\t1\nb\nc\td\te\n",
+			Opts:    LoadOptions{Format: FormatTSV},
+			ExpData: map[string]interface{}{"ab": "1", "b": "", "c": "d"},
+		},
+		{
+			Name:       "tsv columns are selected",
+			Input:      "1\tab\tx\n2\tb\ty\n3\n",
+			Opts:       LoadOptions{Format: FormatTSV, KeyColumn: 2, DataColumn: 1},
+			ExpData:    map[string]interface{}{"ab": "1", "b": "2"},
+			ExpLineErr: []string{"line 3: no column 2 for the word"},
+		},
+		{
+			Name:      "negative key column is rejected",
+			Input:     "1\tab\n",
+			Opts:      LoadOptions{Format: FormatTSV, KeyColumn: -2},
+			ExpectErr: "key column -2 is not a column",
+		},
+		{
+			Name:       "csv lines are loaded with quoting",
+			Input:      "\"a,b\",1\nc;d,2\n\"broken\n",
+			Opts:       LoadOptions{Format: FormatCSV},
+			ExpData:    map[string]interface{}{"a,b": "1", "c;d": "2"},
+			ExpLineErr: []string{"line 3: invalid CSV"},
+		},
+		{
+			Name:    "csv delimiter is configurable",
+			Input:   "a,b;1\n",
+			Opts:    LoadOptions{Format: FormatCSV, Delimiter: ';'},
+			ExpData: map[string]interface{}{"a,b": "1"},
+		},
+		{
+			Name:       "json lines are loaded with data",
+			Input:      "{\"word\":\"ab\",\"data\":1}\n{\"word\":\"b\"}\n{\"data\":2}\nnot json\n",
+			Opts:       LoadOptions{Format: FormatJSONLines},
+			ExpData:    map[string]interface{}{"ab": float64(1), "b": nil},
+			ExpLineErr: []string{"line 3: no string field word", "line 4: invalid JSON"},
+		},
+		{
+			Name:    "json fields are selected",
+			Input:   "{\"term\":\"ab\",\"score\":[1,2]}\n",
+			Opts:    LoadOptions{Format: FormatJSONLines, KeyField: "term", DataField: "score"},
+			ExpData: map[string]interface{}{"ab": []interface{}{float64(1), float64(2)}},
+		},
+		{
+			Name:    "gzip input is decompressed",
+			Input:   "ab\nb\n",
+			Gzip:    true,
+			ExpData: map[string]interface{}{"ab": "", "b": ""},
+		},
+		{
+			Name:       "repeated words are reported with their line",
+			Input:      "ab\nb\nab\n",
+			ExpData:    map[string]interface{}{"ab": "", "b": ""},
+			ExpLineErr: []string{"line 3: word ab already exists in trie"},
+		},
+		{
+			Name:      "read error throws error",
+			ExpectErr: "could not read line 1: connection reset",
+		},
+	}
+
+	for _, test := range cases {
+		var r io.Reader = strings.NewReader(test.Input)
+		if test.Gzip {
+			buf := &bytes.Buffer{}
+			gz := gzip.NewWriter(buf)
+			_, err := gz.Write([]byte(test.Input))
+			require.Empty(t, err, test.Name)
+			require.Empty(t, gz.Close(), test.Name)
+			r = buf
+		}
+		if test.ExpectErr != "" {
+			r = failingReader{}
+		}
+
+		opts := test.Opts
+		opts.Name = "test"
+		tr, err := NewFromReader(r, opts)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		if test.ExpLineErr != nil {
+			var loadErr *LoadError
+			require.True(t, errors.As(err, &loadErr), test.Name)
+			require.Len(t, loadErr.Lines, len(test.ExpLineErr), test.Name)
+			for i, expErr := range test.ExpLineErr {
+				assert.Contains(t, loadErr.Lines[i].Error(), expErr, test.Name)
+			}
+		} else {
+			require.Empty(t, err, test.Name)
+		}
+		assert.Equal(t, "test", tr.Name, test.Name)
+
+		data := map[string]interface{}{}
+		for _, word := range tr.Words() {
+			n, err := tr.Find(word)
+			require.Empty(t, err, test.Name)
+			data[word] = n.Data()
+		}
+		assert.Equal(t, test.ExpData, data, test.Name)
+	}
+}
+
+func TestNewFromReaderLongLine(t *testing.T) {
+	// bufio.Scanner gives up on lines over 64 KB
+	long := strings.Repeat("x", 65*1024)
+
+	tr, err := NewFromReader(strings.NewReader("ab\n"+long+"\nb\n"), LoadOptions{})
+	require.Empty(t, err)
+
+	for _, word := range []string{"ab", long, "b"} {
+		_, err := tr.Find(word)
+		assert.Empty(t, err)
+	}
+}
+
+func TestAddFromReader(t *testing.T) {
+	tr := New("words")
+	require.NoError(t, tr.AddFromReader(strings.NewReader("car\ncart\n"), LoadOptions{}))
+
+	err := tr.AddFromReader(strings.NewReader("dog\ncar\n"), LoadOptions{})
+	var loadErr *LoadError
+	require.ErrorAs(t, err, &loadErr)
+	assert.Equal(t, []LineError{{Line: 2, Err: &ExistsError{Word: "car"}}}, loadErr.Lines)
+	assert.ElementsMatch(t, []string{"car", "cart", "dog"}, tr.Words())
+}
@@ -0,0 +1,117 @@
+package trie
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UpdateFunc gives the new data of a word from its current data, exists telling if the word is in
+// the trie. Returning false for keep removes the word, or leaves it out if it is not in the trie
+type UpdateFunc func(old interface{}, exists bool) (data interface{}, keep bool)
+
+// Put adds the word with its data, replacing the data and the stored spelling if the word is already
+// in the trie. The previous data is returned with replaced set if the word was in the trie
+func (t *Trie) Put(word string, data interface{}) (old interface{}, replaced bool, err error) {
+	_, err = t.update(word, func(cur interface{}, exists bool) (interface{}, bool) {
+		old, replaced = cur, exists
+		return data, true
+	}, true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return old, replaced, nil
+}
+
+// AddIfAbsent adds the word with its data if it is not in the trie. The node of the word is
+// returned either way, with added set if the word was added
+func (t *Trie) AddIfAbsent(word string, data interface{}) (n Node, added bool, err error) {
+	n, err = t.update(word, func(cur interface{}, exists bool) (interface{}, bool) {
+		if exists {
+			return cur, true
+		}
+		added = true
+		return data, true
+	}, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return expose(n), added, nil
+}
+
+// Update calls fn with the data of the word and stores the data returned, adding the word if it is
+// not in the trie or removing it if fn does not keep it. A word whose time to live has passed is not
+// in the trie. The path of the word is descended once, so
+// fn sees and replaces the data in a single step. The node of the word is returned, or nil if the
+// word is not in the trie afterwards
+func (t *Trie) Update(word string, fn UpdateFunc) (Node, error) {
+	termNode, err := t.update(word, fn, false)
+	if err != nil || termNode == nil {
+		return nil, err
+	}
+	return expose(termNode), nil
+}
+
+// update changes the data of the word like Update without handing out its node. The stored spelling
+// of a word already in the trie is replaced by word if respell is set
+func (t *Trie) update(word string, fn UpdateFunc, respell bool) (Node, error) {
+	if len(word) == 0 {
+		return nil, errorf(ErrEmptyKey, "no string to update")
+	}
+
+	key := t.key(word)
+	if len(key) == 0 {
+		return nil, errorf(ErrEmptyKey, "no string to update")
+	}
+
+	t.reap(key)
+	termNode := t.addPath(t.root(), []rune(key))
+
+	exists := termNode.IsTerminal()
+	var old interface{}
+	if exists {
+		old = termNode.Data()
+	}
+
+	data, keep := fn(old, exists)
+	if !keep {
+		// Prunes the nodes added for the word as well as the word itself, once the suffix index
+		// has let go of the word
+		spelling := t.spelling(key)
+		if exists {
+			if err := t.unindexKey(key); err != nil {
+				return nil, fmt.Errorf("could not remove word %s from suffix index: %s", word, err)
+			}
+		}
+		t.unterminate(termNode)
+		if exists {
+			t.emit(Change{Type: Removed, Key: spelling, Old: old})
+		}
+		return nil, nil
+	}
+
+	if !exists {
+		if err := t.indexKey(key, word); err != nil {
+			t.unterminate(termNode)
+			return nil, fmt.Errorf("could not add word %s to suffix index: %s", word, err)
+		}
+	}
+	t.setTerm(termNode, true)
+	t.setData(termNode, data)
+	if exists {
+		if respell {
+			t.setSpelling(key, word)
+		}
+		t.touch(key)
+	}
+	switch {
+	case !exists:
+		t.emit(Change{Type: Added, Key: word, New: data})
+		t.evict(key)
+	case !reflect.DeepEqual(old, data):
+		t.emit(Change{Type: Changed, Key: t.spelling(key), Old: old, New: data})
+	}
+
+	return termNode, nil
+}
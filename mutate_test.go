@@ -0,0 +1,249 @@
+package trie
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPut(t *testing.T) {
+	tr := New("put", WithSuffixIndex())
+	_, err := tr.Add("card", 1)
+	require.NoError(t, err)
+
+	var cases = []struct {
+		Name        string
+		Word        string
+		Data        interface{}
+		ExpOld      interface{}
+		ExpReplaced bool
+		ExpectErr   string
+	}{
+		{
+			Name:        "existing word is replaced",
+			Word:        "card",
+			Data:        2,
+			ExpOld:      1,
+			ExpReplaced: true,
+		},
+		{
+			Name: "new word is added",
+			Word: "car",
+			Data: 3,
+		},
+		{
+			Name:      "empty word throws error",
+			ExpectErr: "no string to update",
+		},
+	}
+
+	for _, test := range cases {
+		old, replaced, err := tr.Put(test.Word, test.Data)
+		if test.ExpectErr != "" {
+			assert.EqualError(t, err, test.ExpectErr, test.Name)
+			assert.ErrorIs(t, err, ErrEmptyKey, test.Name)
+			continue
+		}
+
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, test.ExpOld, old, test.Name)
+		assert.Equal(t, test.ExpReplaced, replaced, test.Name)
+
+		n, err := tr.Find(test.Word)
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, test.Data, n.Data(), test.Name)
+	}
+
+	assert.ElementsMatch(t, []string{"car", "card"}, tr.Words())
+	suffixed, err := tr.WordsWithSuffix("r")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"car"}, suffixed)
+}
+
+func TestPutRespells(t *testing.T) {
+	tr := New("put", WithCaseFolding())
+	_, err := tr.Add("Card", 1)
+	require.NoError(t, err)
+
+	_, replaced, err := tr.Put("CARD", 2)
+	require.NoError(t, err)
+	assert.True(t, replaced)
+	assert.Equal(t, []string{"CARD"}, tr.Words())
+
+	// Adding if absent keeps the stored spelling
+	_, added, err := tr.AddIfAbsent("card", 3)
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, []string{"CARD"}, tr.Words())
+}
+
+func TestChangesSkipExpiredWord(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Fun       string
+		ExpExists bool
+		ExpData   interface{}
+	}{
+		{
+			Name:    "put adds expired word anew",
+			Fun:     "Put",
+			ExpData: "new",
+		},
+		{
+			Name:    "add if absent adds expired word",
+			Fun:     "AddIfAbsent",
+			ExpData: "new",
+		},
+		{
+			Name:    "update sees expired word as absent",
+			Fun:     "Update",
+			ExpData: "new",
+		},
+	}
+
+	for _, test := range cases {
+		clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+		var expired []string
+		tr := New("ttl", WithClock(clock.Now), WithExpiration(func(word string, _ interface{}) {
+			expired = append(expired, word)
+		}))
+		_, err := tr.AddWithTTL("token", "old", time.Minute)
+		require.NoError(t, err, test.Name)
+		clock.Advance(time.Minute)
+
+		var exists bool
+		if test.Fun == "Put" {
+			_, exists, err = tr.Put("token", "new")
+		} else if test.Fun == "AddIfAbsent" {
+			var added bool
+			_, added, err = tr.AddIfAbsent("token", "new")
+			exists = !added
+		} else if test.Fun == "Update" {
+			_, err = tr.Update("token", func(old interface{}, ok bool) (interface{}, bool) {
+				exists = ok
+				return "new", true
+			})
+		}
+		require.NoError(t, err, test.Name)
+
+		assert.Equal(t, test.ExpExists, exists, test.Name)
+		assert.Equal(t, []string{"token"}, expired, test.Name)
+		n, err := tr.Find("token")
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, test.ExpData, n.Data(), test.Name)
+		_, ok := tr.TTL("token")
+		assert.False(t, ok, test.Name)
+	}
+}
+
+func TestAddIfAbsent(t *testing.T) {
+	tr := New("absent")
+	_, err := tr.Add("card", 1)
+	require.NoError(t, err)
+
+	n, added, err := tr.AddIfAbsent("card", 2)
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, 1, n.Data())
+
+	n, added, err = tr.AddIfAbsent("ca", 3)
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, 3, n.Data())
+	assert.ElementsMatch(t, []string{"ca", "card"}, tr.Words())
+}
+
+func TestUpdate(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Word     string
+		Keep     bool
+		ExpOld   interface{}
+		ExpFound bool
+		ExpWords []string
+	}{
+		{
+			Name:     "existing word is modified",
+			Word:     "card",
+			Keep:     true,
+			ExpOld:   1,
+			ExpFound: true,
+			ExpWords: []string{"car", "card", "cat"},
+		},
+		{
+			Name:     "missing word is inserted",
+			Word:     "cart",
+			Keep:     true,
+			ExpWords: []string{"car", "card", "cart", "cat"},
+		},
+		{
+			Name:     "existing word is deleted",
+			Word:     "card",
+			ExpOld:   1,
+			ExpFound: true,
+			ExpWords: []string{"car", "cat"},
+		},
+		{
+			Name:     "missing word is not inserted",
+			Word:     "cards",
+			ExpWords: []string{"car", "card", "cat"},
+		},
+		{
+			Name:     "word which is a prefix is deleted without pruning",
+			Word:     "car",
+			ExpOld:   0,
+			ExpFound: true,
+			ExpWords: []string{"card", "cat"},
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("update")
+		for i, word := range []string{"car", "card", "cat"} {
+			_, err := tr.Add(word, i)
+			require.NoError(t, err)
+		}
+		before := tr.Stats().Nodes
+
+		var old interface{}
+		var found bool
+		n, err := tr.Update(test.Word, func(cur interface{}, exists bool) (interface{}, bool) {
+			old, found = cur, exists
+			return "updated", test.Keep
+		})
+		require.NoError(t, err, test.Name)
+
+		assert.Equal(t, test.ExpOld, old, test.Name)
+		assert.Equal(t, test.ExpFound, found, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+
+		if test.Keep {
+			require.NotNil(t, n, test.Name)
+			assert.Equal(t, "updated", n.Data(), test.Name)
+		} else {
+			assert.Nil(t, n, test.Name)
+		}
+		if !test.Keep && !test.ExpFound {
+			assert.Equal(t, before, tr.Stats().Nodes, test.Name)
+		}
+	}
+}
+
+func TestUpdateCounter(t *testing.T) {
+	tr := New("counter")
+	for _, word := range []string{"a", "b", "a", "a"} {
+		_, err := tr.Update(word, func(old interface{}, exists bool) (interface{}, bool) {
+			if !exists {
+				return 1, true
+			}
+			return old.(int) + 1, true
+		})
+		require.NoError(t, err)
+	}
+
+	n, err := tr.Find("a")
+	require.NoError(t, err)
+	assert.Equal(t, 3, n.Data())
+}
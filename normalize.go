@@ -0,0 +1,110 @@
+package trie
+
+import (
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalization defines the Unicode normalization form applied to keys
+type Normalization int
+
+const (
+	// NFC composes characters canonically so that "é" and "é" are the same key
+	NFC Normalization = iota + 1
+	// NFKC also folds compatibility characters so that "ﬁ" and "fi" are the same key
+	NFKC
+)
+
+// keyMode holds the transformations applied to every key before it reaches the nodes
+type keyMode struct {
+	form  Normalization
+	fold  bool
+	strip bool
+}
+
+// WithNormalization normalizes keys to the form specified in Add, Find, Remove and every query
+func WithNormalization(form Normalization) Option {
+	return func(t *Trie) {
+		t.mode().form = form
+	}
+}
+
+// WithCaseFolding folds the case of keys in Add, Find, Remove and every query
+func WithCaseFolding() Option {
+	return func(t *Trie) {
+		t.mode().fold = true
+	}
+}
+
+// WithDiacriticStripping removes combining marks from keys in Add, Find, Remove and every query,
+// so that "café" and "cafe" are the same key
+func WithDiacriticStripping() Option {
+	return func(t *Trie) {
+		t.mode().strip = true
+	}
+}
+
+// mode returns the key mode of the trie, enabling it on first use. Words keep their original
+// spelling once a key mode is enabled
+func (t *Trie) mode() *keyMode {
+	if t.keyMode == nil {
+		t.keyMode = &keyMode{}
+		t.spellings = make(map[string]string)
+	}
+	return t.keyMode
+}
+
+// key gives the key under which the word is stored in the nodes
+func (t *Trie) key(word string) string {
+	return t.keyMode.key(word)
+}
+
+// key applies the key mode to the word. A nil key mode leaves the word as it is
+func (km *keyMode) key(word string) string {
+	if km == nil {
+		return word
+	}
+
+	if km.fold {
+		word = cases.Fold().String(word)
+	}
+
+	if km.strip {
+		strip := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+		if stripped, _, err := transform.String(strip, word); err == nil {
+			word = stripped
+		}
+	}
+
+	switch km.form {
+	case NFC:
+		word = norm.NFC.String(word)
+	case NFKC:
+		word = norm.NFKC.String(word)
+	}
+
+	return word
+}
+
+// spelling gives the word as it was added for the key it is stored under
+func (t *Trie) spelling(key string) string {
+	if word, ok := t.spellings[key]; ok {
+		return word
+	}
+	return key
+}
+
+// spellingsOf replaces the keys in place with the words as they were added
+func (t *Trie) spellingsOf(keys []string) []string {
+	if t.spellings == nil {
+		return keys
+	}
+	for i, key := range keys {
+		keys[i] = t.spelling(key)
+	}
+	return keys
+}
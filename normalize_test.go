@@ -0,0 +1,158 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyModes(t *testing.T) {
+	composed := "Café"
+	decomposed := "Café"
+
+	var cases = []struct {
+		Name     string
+		Opts     []Option
+		Add      string
+		Find     string
+		NotFound bool
+	}{
+		{
+			Name:     "without key mode decomposed word is not found",
+			Add:      composed,
+			Find:     decomposed,
+			NotFound: true,
+		},
+		{
+			Name: "NFC finds decomposed word",
+			Opts: []Option{WithNormalization(NFC)},
+			Add:  composed,
+			Find: decomposed,
+		},
+		{
+			Name: "NFKC finds compatibility ligature",
+			Opts: []Option{WithNormalization(NFKC)},
+			Add:  "ﬁle",
+			Find: "file",
+		},
+		{
+			Name:     "NFC does not fold compatibility ligature",
+			Opts:     []Option{WithNormalization(NFC)},
+			Add:      "ﬁle",
+			Find:     "file",
+			NotFound: true,
+		},
+		{
+			Name: "case folding finds other case",
+			Opts: []Option{WithCaseFolding()},
+			Add:  "Straße",
+			Find: "STRASSE",
+		},
+		{
+			Name: "diacritic stripping finds unaccented word",
+			Opts: []Option{WithDiacriticStripping()},
+			Add:  decomposed,
+			Find: "Cafe",
+		},
+		{
+			Name: "combined modes find folded unaccented word",
+			Opts: []Option{WithNormalization(NFC), WithCaseFolding(), WithDiacriticStripping()},
+			Add:  composed,
+			Find: "CAFE",
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test", test.Opts...)
+		_, err := tr.Add(test.Add, "data")
+		require.Empty(t, err, test.Name)
+
+		n, err := tr.Find(test.Find)
+
+		if test.NotFound {
+			require.NotEmpty(t, err, test.Name)
+			continue
+		}
+		require.Empty(t, err, test.Name)
+		assert.Equal(t, "data", n.Data(), test.Name)
+		assert.Equal(t, []string{test.Add}, tr.Words(), test.Name)
+	}
+}
+
+func TestKeyModeQueries(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Fun      string //add,remove,prefix,longest,suffix
+		Input    string
+		ExpWords []string
+		ExpWord  string
+		ExpErr   string
+	}{
+		{
+			Name:   "adding another spelling of a word throws error",
+			Fun:    "add",
+			Input:  "CAFÉ",
+			ExpErr: "word CAFÉ already exists in trie",
+		},
+		{
+			Name:     "removing another spelling removes the word",
+			Fun:      "remove",
+			Input:    "café",
+			ExpWords: []string{"Cafeteria", "Résumé"},
+		},
+		{
+			Name:     "prefix query keeps original spellings",
+			Fun:      "prefix",
+			Input:    "CAF",
+			ExpWords: []string{"Café", "Cafeteria"},
+		},
+		{
+			Name:    "longest prefix returns original spelling",
+			Fun:     "longest",
+			Input:   "cafés",
+			ExpWord: "Café",
+		},
+		{
+			Name:     "suffix query keeps original spellings",
+			Fun:      "suffix",
+			Input:    "ME",
+			ExpWords: []string{"Résumé"},
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test", WithCaseFolding(), WithDiacriticStripping(), WithSuffixIndex())
+		for _, word := range []string{"Café", "Cafeteria", "Résumé"} {
+			_, err := tr.Add(word, "")
+			require.Empty(t, err, test.Name)
+		}
+
+		var words []string
+		var word string
+		var err error
+		if test.Fun == "add" {
+			_, err = tr.Add(test.Input, "")
+		} else if test.Fun == "remove" {
+			err = tr.Remove(test.Input)
+			words = tr.Words()
+		} else if test.Fun == "prefix" {
+			words = tr.WordsWithPrefix(test.Input)
+		} else if test.Fun == "longest" {
+			word, err = tr.LongestPrefixOf(test.Input)
+		} else if test.Fun == "suffix" {
+			words, err = tr.WordsWithSuffix(test.Input)
+		}
+
+		if test.ExpErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpErr, test.Name)
+			continue
+		}
+		require.Empty(t, err, test.Name)
+		if test.ExpWords != nil {
+			assert.ElementsMatch(t, test.ExpWords, words, test.Name)
+		}
+		assert.Equal(t, test.ExpWord, word, test.Name)
+	}
+}
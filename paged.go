@@ -0,0 +1,875 @@
+package trie
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+const pagedMagic = "TRIEPAGE"
+const pagedVersion = 2
+const defaultPageSize = 4096
+const defaultCachePages = 256
+const minPageSize = 256
+
+// slotSize is the size of a node on a page. Page 0 holds the header, so node 0 never exists and is
+// used as the nil link
+const slotSize = 32
+
+// childSize is the size of a child in a child block, its rune followed by its node
+const childSize = 8
+
+// extentUnit is the unit of the offsets of extents, the child blocks and data values kept in size
+// classes. An extent of class c is extentUnit<<c bytes, and extents of up to a page never span
+// pages
+const extentUnit = 8
+
+// extentClasses is the number of size classes of extents, which cover up to 1 GiB
+const extentClasses = 28
+
+const (
+	slotTerm = 1 << iota
+	slotUsed
+)
+
+// PagedOptions configures a PagedTrie
+type PagedOptions struct {
+	// PageSize is the size of the pages of a new file, 4096 by default. It must be a multiple of 32
+	// of at least 256. An existing file keeps its page size
+	PageSize int
+
+	// CachePages is the number of pages kept in memory, 256 by default
+	CachePages int
+}
+
+// pagedHeader is the state of a paged trie saved at the start of page 0
+type pagedHeader struct {
+	Magic    [8]byte
+	Version  uint32
+	PageSize uint32
+	Pages    uint32
+	Root     uint32
+	FreeHead uint32
+	NodePage uint32
+	NodeNext uint32
+	_        uint32
+	HeapOff  uint64
+	HeapEnd  uint64
+	Words    uint64
+	// FreeExtents holds the first free extent of every size class in units of extentUnit bytes,
+	// linked through their first four bytes
+	FreeExtents [extentClasses]uint32
+}
+
+// slot is a node decoded from its page. The children of the node are kept sorted by rune in a child
+// block, the extent of class class at block, so finding a child reads one page unless the node has
+// more children than fit in a page. A free slot links the next free slot through parent
+type slot struct {
+	value  rune
+	flags  uint8
+	class  uint8
+	parent uint32
+	count  uint32
+	block  uint32
+	valLen uint32
+	valOff uint64
+}
+
+// child is a child of a node as kept in its child block
+type child struct {
+	value rune
+	id    uint32
+}
+
+// PagedTrie defines a trie stored in fixed-size pages of a single file, of which only a bounded
+// number are cached in memory, so it can hold more words than fit in memory. Nodes are slots whose
+// children are kept together in a block, data is gob encoded in extents of the heap, and the slots,
+// blocks and data of removed words are reused. Changes reach the file when pages are evicted and are
+// complete once Flush or Close returns. A PagedTrie is not safe for concurrent use, including lookups
+// which update the page cache
+type PagedTrie struct {
+	file   *os.File
+	header pagedHeader
+	cache  *pageCache
+	slots  uint32
+}
+
+// OpenPaged opens the paged trie in file, creating it if it does not exist
+func OpenPaged(file string, opts PagedOptions) (*PagedTrie, error) {
+	if opts.PageSize == 0 {
+		opts.PageSize = defaultPageSize
+	}
+	if opts.CachePages <= 0 {
+		opts.CachePages = defaultCachePages
+	}
+
+	fh, err := os.OpenFile(file, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %s: %s", file, err)
+	}
+
+	pt := &PagedTrie{file: fh}
+	if err := pt.readHeader(opts.PageSize); err != nil {
+		fh.Close()
+		return nil, err
+	}
+	pt.slots = pt.header.PageSize / slotSize
+	pt.cache = newPageCache(fh, int(pt.header.PageSize), opts.CachePages)
+	// The header is written before any page reaches the file so that it describes them
+	pt.cache.beforeWrite = pt.writeHeader
+
+	if pt.header.Root == 0 {
+		root, err := pt.allocSlot()
+		if err != nil {
+			fh.Close()
+			return nil, err
+		}
+		if err := pt.writeSlot(root, slot{flags: slotUsed}); err != nil {
+			fh.Close()
+			return nil, err
+		}
+		pt.header.Root = root
+
+		// A new file is complete from the start, so a crash never leaves it without a header
+		if err := pt.Flush(); err != nil {
+			fh.Close()
+			return nil, err
+		}
+	}
+
+	return pt, nil
+}
+
+// readHeader reads the header of the file, or starts a new one if the file is empty
+func (pt *PagedTrie) readHeader(pageSize int) error {
+	info, err := pt.file.Stat()
+	if err != nil {
+		return fmt.Errorf("could not read file: %s", err)
+	}
+
+	if info.Size() == 0 {
+		if pageSize < minPageSize || pageSize%slotSize != 0 {
+			return fmt.Errorf("page size %d is not a multiple of %d of at least %d", pageSize, slotSize, minPageSize)
+		}
+		copy(pt.header.Magic[:], pagedMagic)
+		pt.header.Version = pagedVersion
+		pt.header.PageSize = uint32(pageSize)
+		pt.header.Pages = 1
+		return nil
+	}
+
+	sr := io.NewSectionReader(pt.file, 0, int64(binary.Size(pt.header)))
+	if err := binary.Read(sr, binary.BigEndian, &pt.header); err != nil {
+		return fmt.Errorf("could not read header: %s", err)
+	}
+	if string(pt.header.Magic[:]) != pagedMagic || pt.header.Version != pagedVersion {
+		return fmt.Errorf("file is not a paged trie")
+	}
+
+	return nil
+}
+
+// writeHeader writes the header to page 0 of the file
+func (pt *PagedTrie) writeHeader() error {
+	buf := &bytes.Buffer{}
+	if err := binary.Write(buf, binary.BigEndian, pt.header); err != nil {
+		return fmt.Errorf("could not encode header: %s", err)
+	}
+	if _, err := pt.file.WriteAt(buf.Bytes(), 0); err != nil {
+		return fmt.Errorf("could not write header: %s", err)
+	}
+	return nil
+}
+
+// Len gives the number of words in the trie
+func (pt *PagedTrie) Len() int {
+	return int(pt.header.Words)
+}
+
+// Find checks if the trie has the word and returns its data
+func (pt *PagedTrie) Find(word string) (interface{}, error) {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return nil, errorf(ErrEmptyKey, "no string to find")
+	}
+
+	id, s, err := pt.find(runes)
+	if err != nil {
+		return nil, fmt.Errorf("word %s not found: %w", word, err)
+	}
+
+	return pt.readValue(id, s)
+}
+
+// find gives the node where the runes terminate
+func (pt *PagedTrie) find(runes []rune) (uint32, slot, error) {
+	id := pt.header.Root
+	var s slot
+	for pos, r := range runes {
+		cID, _, err := pt.child(id, r)
+		if err != nil {
+			return 0, s, err
+		}
+		if cID == 0 {
+			return 0, s, &NotFoundError{Key: string(runes), Prefix: string(runes[:pos]), Pos: pos}
+		}
+		id = cID
+	}
+
+	s, err := pt.readSlot(id)
+	if err != nil {
+		return 0, s, err
+	}
+	if s.flags&slotTerm == 0 {
+		return 0, s, &NotFoundError{Key: string(runes), Prefix: string(runes), Pos: len(runes)}
+	}
+
+	return id, s, nil
+}
+
+// child gives the child of the node for the rune, or 0, along with the position in the child block
+// of the node where a child for the rune belongs
+func (pt *PagedTrie) child(id uint32, r rune) (uint32, int, error) {
+	s, err := pt.readSlot(id)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	lo, hi := 0, int(s.count)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		c, err := pt.childAt(s, mid)
+		if err != nil {
+			return 0, 0, err
+		}
+		switch {
+		case c.value == r:
+			return c.id, mid, nil
+		case c.value < r:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, lo, nil
+}
+
+// childAt gives the child at position i in the child block of the node
+func (pt *PagedTrie) childAt(s slot, i int) (child, error) {
+	b, err := pt.entry(s.block, i)
+	if err != nil {
+		return child{}, err
+	}
+	return child{
+		value: rune(int32(binary.BigEndian.Uint32(b[0:4]))),
+		id:    binary.BigEndian.Uint32(b[4:8]),
+	}, nil
+}
+
+// entry gives the bytes of the child at position i of the child block in its page
+func (pt *PagedTrie) entry(block uint32, i int) ([]byte, error) {
+	p, off, err := pt.entryPage(block, i)
+	if err != nil {
+		return nil, err
+	}
+	return p.data[off : off+childSize], nil
+}
+
+// Add adds the word with its data to the trie. An error is returned if the word is already in it
+func (pt *PagedTrie) Add(word string, data interface{}) error {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return errorf(ErrEmptyKey, "no string to add")
+	}
+
+	id := pt.header.Root
+	for _, r := range runes {
+		cID, pos, err := pt.child(id, r)
+		if err != nil {
+			return err
+		}
+		if cID == 0 {
+			if cID, err = pt.addChild(id, pos, r); err != nil {
+				return fmt.Errorf("could not add word %s: %s", word, err)
+			}
+		}
+		id = cID
+	}
+
+	s, err := pt.readSlot(id)
+	if err != nil {
+		return err
+	}
+	if s.flags&slotTerm != 0 {
+		return &ExistsError{Word: word}
+	}
+
+	if s.valOff, s.valLen, err = pt.writeValue(data); err != nil {
+		return fmt.Errorf("could not add data of word %s: %s", word, err)
+	}
+	s.flags |= slotTerm
+	if err := pt.writeSlot(id, s); err != nil {
+		return err
+	}
+	pt.header.Words++
+
+	return nil
+}
+
+// addChild adds a node for the rune under the parent at position pos of its child block
+func (pt *PagedTrie) addChild(parent uint32, pos int, r rune) (uint32, error) {
+	ps, err := pt.readSlot(parent)
+	if err != nil {
+		return 0, err
+	}
+	children, err := pt.readChildren(ps)
+	if err != nil {
+		return 0, err
+	}
+	id, err := pt.allocSlot()
+	if err != nil {
+		return 0, err
+	}
+	if err := pt.writeSlot(id, slot{value: r, flags: slotUsed, parent: parent}); err != nil {
+		return 0, err
+	}
+
+	children = append(children, child{})
+	copy(children[pos+1:], children[pos:])
+	children[pos] = child{value: r, id: id}
+	if err := pt.writeChildren(&ps, children); err != nil {
+		return 0, err
+	}
+
+	return id, pt.writeSlot(parent, ps)
+}
+
+// Remove removes the word from the trie and frees the nodes only it used
+func (pt *PagedTrie) Remove(word string) error {
+	runes := []rune(word)
+	if len(runes) == 0 {
+		return errorf(ErrEmptyKey, "no string to remove")
+	}
+
+	id, s, err := pt.find(runes)
+	if err != nil {
+		return fmt.Errorf("could not find word %s in trie: %w", word, err)
+	}
+
+	if err := pt.freeValue(s); err != nil {
+		return err
+	}
+	s.flags &^= slotTerm
+	s.valOff, s.valLen = 0, 0
+	if err := pt.writeSlot(id, s); err != nil {
+		return err
+	}
+	pt.header.Words--
+
+	for id != pt.header.Root && s.flags&slotTerm == 0 && s.count == 0 {
+		parent := s.parent
+		if err := pt.unlink(parent, s.value); err != nil {
+			return err
+		}
+		if err := pt.writeSlot(id, slot{parent: pt.header.FreeHead}); err != nil {
+			return err
+		}
+		pt.header.FreeHead = id
+
+		id = parent
+		if s, err = pt.readSlot(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// unlink removes the child for the rune from the child block of the parent
+func (pt *PagedTrie) unlink(parent uint32, r rune) error {
+	_, pos, err := pt.child(parent, r)
+	if err != nil {
+		return err
+	}
+
+	ps, err := pt.readSlot(parent)
+	if err != nil {
+		return err
+	}
+	children, err := pt.readChildren(ps)
+	if err != nil {
+		return err
+	}
+	if err := pt.writeChildren(&ps, append(children[:pos], children[pos+1:]...)); err != nil {
+		return err
+	}
+
+	return pt.writeSlot(parent, ps)
+}
+
+// WalkPrefix calls fn with every word beginning with prefix and its data in ascending order of
+// runes until fn returns false
+func (pt *PagedTrie) WalkPrefix(prefix string, fn func(word string, data interface{}) bool) error {
+	id := pt.header.Root
+	runes := []rune(prefix)
+	for _, r := range runes {
+		cID, _, err := pt.child(id, r)
+		if err != nil || cID == 0 {
+			return err
+		}
+		id = cID
+	}
+
+	_, err := pt.walk(id, runes, fn)
+	return err
+}
+
+// walk calls fn with the words at and below the node, returning false once fn does
+func (pt *PagedTrie) walk(id uint32, path []rune, fn func(word string, data interface{}) bool) (bool, error) {
+	s, err := pt.readSlot(id)
+	if err != nil {
+		return false, err
+	}
+
+	if s.flags&slotTerm != 0 {
+		data, err := pt.readValue(id, s)
+		if err != nil {
+			return false, err
+		}
+		if !fn(string(path), data) {
+			return false, nil
+		}
+	}
+
+	// The block is read again for every child as walking the child may evict its page
+	for i := 0; i < int(s.count); i++ {
+		c, err := pt.childAt(s, i)
+		if err != nil {
+			return false, err
+		}
+		if more, err := pt.walk(c.id, append(path, c.value), fn); !more || err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// WordsWithPrefix returns an array of the words in the trie that begin with prefix in ascending
+// order of runes
+func (pt *PagedTrie) WordsWithPrefix(prefix string) ([]string, error) {
+	words := []string{}
+	err := pt.WalkPrefix(prefix, func(word string, _ interface{}) bool {
+		words = append(words, word)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+// Flush writes the header and the changed pages to the file and syncs it
+func (pt *PagedTrie) Flush() error {
+	if err := pt.writeHeader(); err != nil {
+		return err
+	}
+	if err := pt.cache.flush(); err != nil {
+		return err
+	}
+	if err := pt.file.Sync(); err != nil {
+		return fmt.Errorf("could not sync file: %s", err)
+	}
+
+	return nil
+}
+
+// Close flushes and closes the file
+func (pt *PagedTrie) Close() error {
+	if err := pt.Flush(); err != nil {
+		pt.file.Close()
+		return err
+	}
+	return pt.file.Close()
+}
+
+// allocSlot gives a free node, reusing the slots of removed nodes first
+func (pt *PagedTrie) allocSlot() (uint32, error) {
+	if id := pt.header.FreeHead; id != 0 {
+		s, err := pt.readSlot(id)
+		if err != nil {
+			return 0, err
+		}
+		pt.header.FreeHead = s.parent
+		return id, nil
+	}
+
+	if pt.header.NodePage == 0 || pt.header.NodeNext == pt.slots {
+		pt.header.NodePage = pt.allocPages(1)
+		pt.header.NodeNext = 0
+	}
+	id := pt.header.NodePage*pt.slots + pt.header.NodeNext
+	pt.header.NodeNext++
+
+	return id, nil
+}
+
+// allocPages adds n contiguous pages at the end of the file and gives the first one
+func (pt *PagedTrie) allocPages(n uint32) uint32 {
+	first := pt.header.Pages
+	pt.header.Pages += n
+	return first
+}
+
+// readChildren gives a copy of the children of the node
+func (pt *PagedTrie) readChildren(s slot) ([]child, error) {
+	children := make([]child, s.count, s.count+1)
+	for i := range children {
+		c, err := pt.childAt(s, i)
+		if err != nil {
+			return nil, err
+		}
+		children[i] = c
+	}
+
+	return children, nil
+}
+
+// writeChildren stores the children of the node in its child block, moving them to a block of a
+// larger class if they do not fit and freeing the block once there are none. The slot is updated
+// but not written
+func (pt *PagedTrie) writeChildren(s *slot, children []child) error {
+	if len(children) == 0 {
+		if s.block != 0 {
+			if err := pt.freeExtent(s.block, s.class); err != nil {
+				return err
+			}
+		}
+		s.block, s.class, s.count = 0, 0, 0
+		return nil
+	}
+
+	if s.block == 0 || len(children) > 1<<s.class {
+		class := extentClass(uint64(len(children)) * childSize)
+		block, err := pt.allocExtent(class)
+		if err != nil {
+			return err
+		}
+		if s.block != 0 {
+			if err := pt.freeExtent(s.block, s.class); err != nil {
+				return err
+			}
+		}
+		s.block, s.class = block, class
+	}
+
+	for i, c := range children {
+		p, off, err := pt.entryPage(s.block, i)
+		if err != nil {
+			return err
+		}
+		binary.BigEndian.PutUint32(p.data[off:], uint32(int32(c.value)))
+		binary.BigEndian.PutUint32(p.data[off+4:], c.id)
+		p.dirty = true
+	}
+	s.count = uint32(len(children))
+
+	return nil
+}
+
+// entryPage gives the page of the child at position i of the child block and its offset in it. A
+// child never spans pages
+func (pt *PagedTrie) entryPage(block uint32, i int) (*page, uint64, error) {
+	pageSize := uint64(pt.header.PageSize)
+	off := uint64(block)*extentUnit + uint64(i)*childSize
+	p, err := pt.cache.get(uint32(off / pageSize))
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, off % pageSize, nil
+}
+
+// extentClass gives the class of the smallest extent holding size bytes
+func extentClass(size uint64) uint8 {
+	class := uint8(0)
+	for size > extentUnit<<class {
+		class++
+	}
+	return class
+}
+
+// allocExtent gives a free extent of the class, reusing the extents of the free list of the class
+// first. Extents of up to a page are taken from the heap page and never span pages, while larger
+// ones get contiguous pages of their own
+func (pt *PagedTrie) allocExtent(class uint8) (uint32, error) {
+	if unit := pt.header.FreeExtents[class]; unit != 0 {
+		next := make([]byte, 4)
+		if err := pt.cache.readAt(next, uint64(unit)*extentUnit); err != nil {
+			return 0, err
+		}
+		pt.header.FreeExtents[class] = binary.BigEndian.Uint32(next)
+		return unit, nil
+	}
+
+	pageSize := uint64(pt.header.PageSize)
+	size := uint64(extentUnit) << class
+	if size > pageSize {
+		n := (size + pageSize - 1) / pageSize
+		return uint32(uint64(pt.allocPages(uint32(n))) * pageSize / extentUnit), nil
+	}
+
+	if pt.header.HeapEnd-pt.header.HeapOff < size {
+		pt.header.HeapOff = uint64(pt.allocPages(1)) * pageSize
+		pt.header.HeapEnd = pt.header.HeapOff + pageSize
+	}
+	unit := uint32(pt.header.HeapOff / extentUnit)
+	pt.header.HeapOff += size
+
+	return unit, nil
+}
+
+// freeExtent adds the extent to the free list of its class
+func (pt *PagedTrie) freeExtent(unit uint32, class uint8) error {
+	next := make([]byte, 4)
+	binary.BigEndian.PutUint32(next, pt.header.FreeExtents[class])
+	if err := pt.cache.writeAt(next, uint64(unit)*extentUnit); err != nil {
+		return err
+	}
+	pt.header.FreeExtents[class] = unit
+	return nil
+}
+
+// readSlot decodes the node
+func (pt *PagedTrie) readSlot(id uint32) (slot, error) {
+	p, err := pt.cache.get(id / pt.slots)
+	if err != nil {
+		return slot{}, err
+	}
+
+	b := p.data[(id%pt.slots)*slotSize:]
+	return slot{
+		value:  rune(int32(binary.BigEndian.Uint32(b[0:4]))),
+		flags:  b[4],
+		class:  b[5],
+		parent: binary.BigEndian.Uint32(b[8:12]),
+		count:  binary.BigEndian.Uint32(b[12:16]),
+		block:  binary.BigEndian.Uint32(b[16:20]),
+		valLen: binary.BigEndian.Uint32(b[20:24]),
+		valOff: binary.BigEndian.Uint64(b[24:32]),
+	}, nil
+}
+
+// writeSlot encodes the node in its page
+func (pt *PagedTrie) writeSlot(id uint32, s slot) error {
+	p, err := pt.cache.get(id / pt.slots)
+	if err != nil {
+		return err
+	}
+
+	b := p.data[(id%pt.slots)*slotSize:]
+	binary.BigEndian.PutUint32(b[0:4], uint32(int32(s.value)))
+	b[4] = s.flags
+	b[5] = s.class
+	binary.BigEndian.PutUint32(b[8:12], s.parent)
+	binary.BigEndian.PutUint32(b[12:16], s.count)
+	binary.BigEndian.PutUint32(b[16:20], s.block)
+	binary.BigEndian.PutUint32(b[20:24], s.valLen)
+	binary.BigEndian.PutUint64(b[24:32], s.valOff)
+	p.dirty = true
+
+	return nil
+}
+
+// pagedValue wraps data so any registered type can be gob encoded
+type pagedValue struct {
+	Data interface{}
+}
+
+// writeValue writes the encoded data to a free extent and gives its offset in the file and length.
+// Nil data takes no space
+func (pt *PagedTrie) writeValue(data interface{}) (uint64, uint32, error) {
+	if data == nil {
+		return 0, 0, nil
+	}
+
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(pagedValue{Data: data}); err != nil {
+		return 0, 0, err
+	}
+	if uint64(buf.Len()) > extentUnit<<(extentClasses-1) {
+		return 0, 0, fmt.Errorf("data of %d bytes is too large", buf.Len())
+	}
+
+	unit, err := pt.allocExtent(extentClass(uint64(buf.Len())))
+	if err != nil {
+		return 0, 0, err
+	}
+	off := uint64(unit) * extentUnit
+	if err := pt.cache.writeAt(buf.Bytes(), off); err != nil {
+		return 0, 0, err
+	}
+
+	return off, uint32(buf.Len()), nil
+}
+
+// freeValue frees the extent of the data of the node
+func (pt *PagedTrie) freeValue(s slot) error {
+	if s.valLen == 0 {
+		return nil
+	}
+	return pt.freeExtent(uint32(s.valOff/extentUnit), extentClass(uint64(s.valLen)))
+}
+
+// readValue decodes the data of the node
+func (pt *PagedTrie) readValue(id uint32, s slot) (interface{}, error) {
+	if s.valLen == 0 {
+		return nil, nil
+	}
+
+	b := make([]byte, s.valLen)
+	if err := pt.cache.readAt(b, s.valOff); err != nil {
+		return nil, err
+	}
+
+	v := pagedValue{}
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&v); err != nil {
+		return nil, fmt.Errorf("could not decode data of node %d: %s", id, err)
+	}
+
+	return v.Data, nil
+}
+
+// page is a page of the file held in the cache
+type page struct {
+	no    uint32
+	data  []byte
+	dirty bool
+}
+
+// pageCache keeps the most recently used pages of a file in memory, writing changed pages back
+// when they are evicted
+type pageCache struct {
+	file     *os.File
+	pageSize int
+	capacity int
+	pages    map[uint32]*list.Element
+	lru      *list.List
+	// beforeWrite is called before a changed page is evicted to the file
+	beforeWrite func() error
+}
+
+// newPageCache creates a cache of capacity pages of the file
+func newPageCache(file *os.File, pageSize int, capacity int) *pageCache {
+	return &pageCache{
+		file:     file,
+		pageSize: pageSize,
+		capacity: capacity,
+		pages:    make(map[uint32]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get gives the page, reading it from the file if it is not cached. Pages past the end of the file
+// are empty
+func (c *pageCache) get(no uint32) (*page, error) {
+	if e, ok := c.pages[no]; ok {
+		c.lru.MoveToFront(e)
+		return e.Value.(*page), nil
+	}
+
+	p := &page{no: no, data: make([]byte, c.pageSize)}
+	_, err := c.file.ReadAt(p.data, int64(no)*int64(c.pageSize))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not read page %d: %s", no, err)
+	}
+
+	for c.lru.Len() >= c.capacity {
+		if err := c.evict(); err != nil {
+			return nil, err
+		}
+	}
+	c.pages[no] = c.lru.PushFront(p)
+
+	return p, nil
+}
+
+// evict removes the least recently used page, writing it back if it changed
+func (c *pageCache) evict() error {
+	e := c.lru.Back()
+	p := e.Value.(*page)
+	if p.dirty && c.beforeWrite != nil {
+		if err := c.beforeWrite(); err != nil {
+			return err
+		}
+	}
+	if err := c.write(p); err != nil {
+		return err
+	}
+
+	c.lru.Remove(e)
+	delete(c.pages, p.no)
+	return nil
+}
+
+// write writes the page back to the file if it changed
+func (c *pageCache) write(p *page) error {
+	if !p.dirty {
+		return nil
+	}
+	if _, err := c.file.WriteAt(p.data, int64(p.no)*int64(c.pageSize)); err != nil {
+		return fmt.Errorf("could not write page %d: %s", p.no, err)
+	}
+	p.dirty = false
+	return nil
+}
+
+// flush writes every changed page back to the file
+func (c *pageCache) flush() error {
+	for e := c.lru.Front(); e != nil; e = e.Next() {
+		if err := c.write(e.Value.(*page)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readAt fills b from the pages at offset off of the file
+func (c *pageCache) readAt(b []byte, off uint64) error {
+	return c.span(b, off, false)
+}
+
+// writeAt copies b into the pages at offset off of the file
+func (c *pageCache) writeAt(b []byte, off uint64) error {
+	return c.span(b, off, true)
+}
+
+// span copies b into the pages it spans from offset off if write is set, or the pages into b
+func (c *pageCache) span(b []byte, off uint64, write bool) error {
+	pageSize := uint64(c.pageSize)
+	for len(b) > 0 {
+		p, err := c.get(uint32(off / pageSize))
+		if err != nil {
+			return err
+		}
+
+		data := p.data[off%pageSize:]
+		var n int
+		if write {
+			n = copy(data, b)
+			p.dirty = true
+		} else {
+			n = copy(b, data)
+		}
+
+		b = b[n:]
+		off += uint64(n)
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package trie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPagedTrie(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.pages")
+	pt, err := OpenPaged(file, PagedOptions{PageSize: 256, CachePages: 4})
+	require.NoError(t, err)
+
+	var cases = []struct {
+		Name      string
+		Op        string
+		Word      string
+		Data      interface{}
+		ExpData   interface{}
+		ExpectErr string
+	}{
+		{Name: "word is added", Op: "add", Word: "card", Data: 1},
+		{Name: "prefix is added", Op: "add", Word: "car", Data: "two"},
+		{Name: "sibling is added", Op: "add", Word: "cat"},
+		{Name: "word with a long value is added", Op: "add", Word: "cab", Data: fmt.Sprintf("%0600d", 3)},
+		{Name: "word is found", Op: "find", Word: "card", ExpData: 1},
+		{Name: "word without data is found", Op: "find", Word: "cat"},
+		{Name: "word with a long value is found", Op: "find", Word: "cab", ExpData: fmt.Sprintf("%0600d", 3)},
+		{
+			Name:      "existing word throws error",
+			Op:        "add",
+			Word:      "car",
+			ExpectErr: "word car already exists in trie",
+		},
+		{
+			Name:      "non terminated path is not found",
+			Op:        "find",
+			Word:      "ca",
+			ExpectErr: "word ca not found: string ca not found but exists as a non-terminated path",
+		},
+		{
+			Name:      "missing word is not found",
+			Op:        "find",
+			Word:      "cow",
+			ExpectErr: "word cow not found: string cow not found, longest prefix found: c",
+		},
+		{Name: "word is removed", Op: "remove", Word: "card"},
+		{Name: "prefix of removed word is kept", Op: "find", Word: "car", ExpData: "two"},
+		{
+			Name:      "removed word is not found",
+			Op:        "remove",
+			Word:      "card",
+			ExpectErr: "could not find word card in trie",
+		},
+		{
+			Name:      "empty word throws error",
+			Op:        "add",
+			ExpectErr: "no string to add",
+		},
+	}
+
+	for _, test := range cases {
+		var err error
+		var data interface{}
+		switch test.Op {
+		case "add":
+			err = pt.Add(test.Word, test.Data)
+		case "find":
+			data, err = pt.Find(test.Word)
+		case "remove":
+			err = pt.Remove(test.Word)
+		}
+
+		if test.ExpectErr != "" {
+			require.Error(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		require.NoError(t, err, test.Name)
+		assert.Equal(t, test.ExpData, data, test.Name)
+	}
+
+	words, err := pt.WordsWithPrefix("ca")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"cab", "car", "cat"}, words)
+	assert.Equal(t, 3, pt.Len())
+	require.NoError(t, pt.Close())
+}
+
+func TestPagedTrieLargerThanCache(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.pages")
+	opts := PagedOptions{PageSize: 256, CachePages: 3}
+	pt, err := OpenPaged(file, opts)
+	require.NoError(t, err)
+
+	tr := New("words")
+	for i := 0; i < 2000; i++ {
+		word := fmt.Sprintf("w%d", i*7919%2000)
+		require.NoError(t, pt.Add(word, i))
+		_, err := tr.Add(word, i)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, pt.cache.lru.Len(), opts.CachePages)
+	}
+	for i := 0; i < 2000; i += 3 {
+		word := fmt.Sprintf("w%d", i)
+		require.NoError(t, pt.Remove(word))
+		require.NoError(t, tr.Remove(word))
+	}
+	require.NoError(t, pt.Close())
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	size := info.Size()
+
+	pt, err = OpenPaged(file, PagedOptions{CachePages: 3})
+	require.NoError(t, err)
+	assert.Equal(t, len(tr.Words()), pt.Len())
+
+	expWords := tr.WordsWithPrefix("w1")
+	sort.Strings(expWords)
+	words, err := pt.WordsWithPrefix("w1")
+	require.NoError(t, err)
+	assert.Equal(t, expWords, words)
+
+	for _, word := range tr.Words() {
+		n, _ := tr.Find(word)
+		data, err := pt.Find(word)
+		require.NoError(t, err, word)
+		assert.Equal(t, n.Data(), data, word)
+	}
+
+	// Words without data fit in the slots freed by removals
+	for i := 0; i < 2000; i += 3 {
+		require.NoError(t, pt.Add(fmt.Sprintf("w%d", i), nil))
+	}
+	require.NoError(t, pt.Flush())
+	info, err = os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, size, info.Size())
+
+	// Walking stops when asked to
+	count := 0
+	require.NoError(t, pt.WalkPrefix("", func(string, interface{}) bool {
+		count++
+		return count < 5
+	}))
+	assert.Equal(t, 5, count)
+	require.NoError(t, pt.Close())
+}
+
+func TestOpenPagedErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := OpenPaged(filepath.Join(dir, "small.pages"), PagedOptions{PageSize: 100})
+	assert.EqualError(t, err, "page size 100 is not a multiple of 32 of at least 256")
+
+	garbage := filepath.Join(dir, "garbage.pages")
+	require.NoError(t, os.WriteFile(garbage, make([]byte, 256), 0o644))
+	_, err = OpenPaged(garbage, PagedOptions{})
+	assert.EqualError(t, err, "file is not a paged trie")
+}
+
+func TestPagedTrieCrash(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.pages")
+	pt, err := OpenPaged(file, PagedOptions{PageSize: 256, CachePages: 2})
+	require.NoError(t, err)
+
+	// Pages are evicted to the file but the trie is never flushed
+	for i := 0; i < 500; i++ {
+		require.NoError(t, pt.Add(fmt.Sprintf("w%d", i), i))
+	}
+	require.NoError(t, pt.file.Close())
+
+	pt, err = OpenPaged(file, PagedOptions{CachePages: 2})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, pt.Len(), 500)
+	require.NoError(t, pt.Add("fresh", 1))
+	data, err := pt.Find("fresh")
+	require.NoError(t, err)
+	assert.Equal(t, 1, data)
+	require.NoError(t, pt.Close())
+}
+
+func TestPagedTrieFanout(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.pages")
+	pt, err := OpenPaged(file, PagedOptions{PageSize: 256, CachePages: 3})
+	require.NoError(t, err)
+
+	// The children of the root span many pages of 256 bytes
+	for i := 0; i < 2000; i++ {
+		require.NoError(t, pt.Add(string(rune(0x4e00+i*7919%2000)), i*7919%2000))
+	}
+	for i := 0; i < 2000; i += 2 {
+		require.NoError(t, pt.Remove(string(rune(0x4e00+i))))
+	}
+	require.NoError(t, pt.Close())
+
+	pt, err = OpenPaged(file, PagedOptions{CachePages: 3})
+	require.NoError(t, err)
+	var expWords []string
+	for i := 1; i < 2000; i += 2 {
+		word := string(rune(0x4e00 + i))
+		expWords = append(expWords, word)
+		data, err := pt.Find(word)
+		require.NoError(t, err, word)
+		assert.Equal(t, i, data, word)
+	}
+	_, err = pt.Find(string(rune(0x4e00)))
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	found, err := pt.WordsWithPrefix("")
+	require.NoError(t, err)
+	assert.Equal(t, expWords, found)
+	require.NoError(t, pt.Close())
+}
+
+func TestPagedTrieReusesData(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.pages")
+	pt, err := OpenPaged(file, PagedOptions{PageSize: 256, CachePages: 3})
+	require.NoError(t, err)
+
+	churn := func() {
+		for i := 0; i < 500; i++ {
+			word := fmt.Sprintf("w%d", i%50)
+			require.NoError(t, pt.Add(word, fmt.Sprintf("%0*d", i%300, i)))
+			require.NoError(t, pt.Remove(word))
+		}
+	}
+
+	// The slots, blocks and data of removed words are reused by the next ones
+	churn()
+	pages := pt.header.Pages
+	for round := 0; round < 4; round++ {
+		churn()
+	}
+	assert.Equal(t, pages, pt.header.Pages)
+	assert.Equal(t, 0, pt.Len())
+	require.NoError(t, pt.Close())
+}
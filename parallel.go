@@ -0,0 +1,138 @@
+package trie
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// loadBatchSize is the number of lines sent to a worker at once
+const loadBatchSize = 1024
+
+// loadEntry is a parsed line waiting to be added by a worker
+type loadEntry struct {
+	line int
+	word string
+	data interface{}
+}
+
+// loadWorker builds the sub-trie of the words whose keys start with the runes of its partition
+type loadWorker struct {
+	trie    *Trie
+	batches chan []loadEntry
+	errs    []LineError
+}
+
+// loadParallel reads and parses the lines, sends them to the workers partitioned by the first rune
+// of their key and grafts the sub-tries of the workers under the root of a single trie. Every word
+// goes to the same worker in input order, so repeated words are reported on the same lines as a
+// sequential load. Capacity options are rejected, as every worker would only bound its own words
+func (opts LoadOptions) loadParallel(br *bufio.Reader) (*Trie, error) {
+	tr := New(opts.Name, opts.Options...)
+	if tr.usage != nil {
+		return nil, fmt.Errorf("capacity options cannot be used with %d workers", opts.Workers)
+	}
+
+	var wg sync.WaitGroup
+	workers := make([]*loadWorker, opts.Workers)
+	for i := range workers {
+		w := &loadWorker{
+			trie:    New(opts.Name, opts.Options...),
+			batches: make(chan []loadEntry, 4),
+		}
+		workers[i] = w
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range w.batches {
+				for _, entry := range batch {
+					if _, err := w.trie.add(entry.word, entry.data); err != nil {
+						w.errs = append(w.errs, LineError{Line: entry.line, Err: err})
+					}
+				}
+			}
+		}()
+	}
+
+	loadErr := &LoadError{}
+	pending := make([][]loadEntry, len(workers))
+	readErr := opts.readLines(br, loadErr, func(lineNo int, word string, data interface{}) {
+		i := 0
+		if r, _ := utf8.DecodeRuneInString(tr.key(word)); r != utf8.RuneError {
+			i = int(uint32(r) % uint32(len(workers)))
+		}
+		pending[i] = append(pending[i], loadEntry{line: lineNo, word: word, data: data})
+		if len(pending[i]) == loadBatchSize {
+			workers[i].batches <- pending[i]
+			pending[i] = nil
+		}
+	})
+
+	for i, w := range workers {
+		if len(pending[i]) > 0 {
+			w.batches <- pending[i]
+		}
+		close(w.batches)
+	}
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	for _, w := range workers {
+		tr.graft(w.trie)
+		loadErr.Lines = append(loadErr.Lines, w.errs...)
+	}
+
+	if len(loadErr.Lines) > 0 {
+		sort.Slice(loadErr.Lines, func(i, j int) bool {
+			return loadErr.Lines[i].Line < loadErr.Lines[j].Line
+		})
+		return tr, loadErr
+	}
+
+	return tr, nil
+}
+
+// graft moves the children of the root of sub under the root of the trie along with their spellings,
+// copying them if the nodes are in another arena. The first runes of the two tries must not overlap.
+// Reversed words do not partition the same way, so the suffix index of sub is merged into the one
+// of the trie instead. Loaded words have no time to live, and the trie already has the expiration
+// options of sub
+func (t *Trie) graft(sub *Trie) {
+	root := t.root().(trieNode)
+	for _, cNode := range sub.root().SortedChildren() {
+		root.attachChild(cNode)
+	}
+
+	for key, word := range sub.spellings {
+		t.spellings[key] = word
+	}
+
+	if t.suffix != nil {
+		mergeNodes(t.suffix.root().(trieNode), sub.suffix.root().(trieNode))
+	}
+}
+
+// mergeNodes moves the words at and below src to dst. Children which dst does not have are moved
+// whole and the others are merged in turn
+func mergeNodes(dst, src trieNode) {
+	if src.IsTerminal() {
+		dst.setTerm(true)
+		dst.setData(src.Data())
+	}
+
+	for _, c := range src.appendChildren(nil) {
+		if dc, ok := dst.Child(c.Value()); ok {
+			mergeNodes(dc.(trieNode), c.(trieNode))
+			continue
+		}
+		if moved, ok := src.detachChild(c.Value()); ok {
+			dst.attachChild(moved)
+		}
+	}
+}
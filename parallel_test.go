@@ -0,0 +1,114 @@
+package trie
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFromReaderParallel(t *testing.T) {
+	var words []string
+	for i := 0; i < 1000; i++ {
+		words = append(words, fmt.Sprintf("%c%d\t%d", 'a'+rune(i%26), i*7919%1000, i))
+	}
+	words = append(words, "a1\trepeated", "Éa\t1", "ea\t2", "", "\t3")
+	input := strings.Join(words, "\n")
+
+	var cases = []struct {
+		Name string
+		Opts LoadOptions
+	}{
+		{
+			Name: "plain words",
+			Opts: LoadOptions{Format: FormatTSV},
+		},
+		{
+			Name: "suffix index and folded keys",
+			Opts: LoadOptions{Format: FormatTSV, Options: []Option{
+				WithSuffixIndex(), WithCaseFolding(), WithDiacriticStripping(),
+			}},
+		},
+		{
+			Name: "suffix index in an arena",
+			Opts: LoadOptions{Format: FormatTSV, Options: []Option{WithArena(), WithSuffixIndex()}},
+		},
+	}
+
+	for _, test := range cases {
+		seq, seqErr := NewFromReader(strings.NewReader(input), test.Opts)
+
+		for _, workers := range []int{2, 3, 8} {
+			opts := test.Opts
+			opts.Workers = workers
+			par, parErr := NewFromReader(strings.NewReader(input), opts)
+
+			name := fmt.Sprintf("%s with %d workers", test.Name, workers)
+			assert.Equal(t, seqErr, parErr, name)
+			require.NotNil(t, par, name)
+			assert.Empty(t, Diff(seq, par), name)
+			assert.ElementsMatch(t, seq.Words(), par.Words(), name)
+			assert.Equal(t, seq.Stats(), par.Stats(), name)
+
+			if seq.suffix != nil {
+				assert.Empty(t, Diff(seq.suffix, par.suffix), name)
+			}
+
+			for _, n := range par.Root.Children() {
+				assert.Equal(t, par.Root, n.Parent(), name)
+			}
+		}
+	}
+}
+
+func TestNewFromFileParallel(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "words.txt")
+	require.NoError(t, os.WriteFile(file, []byte("ab\nb\nab\nc\n"), 0o644))
+
+	seq, err := NewFromFile(file, "words")
+	require.NoError(t, err)
+
+	par, err := NewFromFileParallel(file, "words", 4)
+	require.NoError(t, err)
+	assert.Equal(t, "words", par.Name)
+	assert.Empty(t, Diff(seq, par))
+
+	_, err = NewFromFileParallel("", "words", 4)
+	assert.EqualError(t, err, "file is required")
+}
+
+func TestNewFromReaderParallelOptions(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Opts      []Option
+		ExpectErr string
+	}{
+		{
+			Name:      "capacity options are rejected",
+			Opts:      []Option{WithMaxEntries(2, LRU)},
+			ExpectErr: "capacity options cannot be used with 2 workers",
+		},
+		{
+			Name: "expiration options are kept",
+			Opts: []Option{WithExpiration(func(word string, data interface{}) {})},
+		},
+	}
+
+	for _, test := range cases {
+		tr, err := NewFromReader(strings.NewReader("a\nb\nc"), LoadOptions{Workers: 2, Options: test.Opts})
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		require.Empty(t, err, test.Name)
+		assert.ElementsMatch(t, []string{"a", "b", "c"}, tr.Words(), test.Name)
+		require.NotNil(t, tr.ttl, test.Name)
+		assert.NotNil(t, tr.ttl.onExpire, test.Name)
+	}
+}
@@ -0,0 +1,247 @@
+package trie
+
+import (
+	"fmt"
+	"strings"
+)
+
+const pathSeparator = "/"
+const paramPrefix = ':'
+const catchAllPrefix = '*'
+
+// Params maps the names of the parameters in a pattern to the path segments they captured
+type Params map[string]string
+
+// PathTrie defines a trie keyed on "/" separated path segments instead of runes. Patterns can
+// contain static segments, ":name" segments which capture a single segment and a trailing "*name"
+// segment which captures the rest of the path
+type PathTrie struct {
+	root *segmentNode
+}
+
+// segmentNode represents a node in the path trie. Static children are indexed by segment while
+// the parameter and catch-all children are kept apart because they match any segment
+type segmentNode struct {
+	segment  string
+	parent   *segmentNode
+	children map[string]*segmentNode
+	param    *segmentNode
+	catchAll *segmentNode
+	data     interface{}
+
+	pattern string
+	isTerm  bool
+}
+
+// NewPathTrie creates an empty path trie
+func NewPathTrie() *PathTrie {
+	return &PathTrie{
+		root: &segmentNode{
+			children: make(map[string]*segmentNode),
+		},
+	}
+}
+
+// Add adds the pattern with data to the trie. An error is returned if the pattern is malformed,
+// if it conflicts with the parameter names of an existing pattern or if it already exists
+func (pt *PathTrie) Add(pattern string, data interface{}) error {
+	n, err := pt.nodeForPattern(pattern, true)
+	if err != nil {
+		return fmt.Errorf("could not add pattern %s: %w", pattern, err)
+	}
+	if n.isTerm {
+		return errorf(ErrExists, "pattern %s already exists in trie", pattern)
+	}
+
+	n.isTerm = true
+	n.pattern = pattern
+	n.data = data
+
+	return nil
+}
+
+// Find returns the data stored for the pattern. Unlike Lookup the pattern is matched literally, so
+// "/users/:id" only finds the pattern "/users/:id"
+func (pt *PathTrie) Find(pattern string) (interface{}, error) {
+	n, err := pt.nodeForPattern(pattern, false)
+	if err != nil || n == nil || !n.isTerm {
+		return nil, errorf(ErrNotFound, "pattern %s not found", pattern)
+	}
+
+	return n.data, nil
+}
+
+// Remove removes the pattern from the trie and prunes segments no other pattern needs. An error is
+// returned if the pattern is not in the trie
+func (pt *PathTrie) Remove(pattern string) error {
+	n, err := pt.nodeForPattern(pattern, false)
+	if err != nil || n == nil || !n.isTerm {
+		return errorf(ErrNotFound, "could not find pattern %s in trie", pattern)
+	}
+
+	n.isTerm = false
+	n.pattern = ""
+	n.data = nil
+
+	for n.parent != nil && !n.isTerm && len(n.children) == 0 && n.param == nil && n.catchAll == nil {
+		n.parent.removeChild(n)
+		n = n.parent
+	}
+
+	return nil
+}
+
+// Lookup matches the path against the patterns in the trie and returns the data of the matching
+// pattern with the parameters it captured. Static segments are preferred over parameters, which are
+// preferred over catch-alls. The bool is false if no pattern matches
+func (pt *PathTrie) Lookup(path string) (interface{}, Params, bool) {
+	params := Params{}
+
+	n := pt.root.match(splitPath(path), params)
+	if n == nil {
+		return nil, nil, false
+	}
+
+	return n.data, params, true
+}
+
+// Patterns returns an array of the patterns in the trie
+func (pt *PathTrie) Patterns() []string {
+	patterns := []string{}
+	pt.root.patterns(&patterns)
+	return patterns
+}
+
+// nodeForPattern walks the segments of the pattern and returns the node where it ends. Missing
+// nodes are created if create is set, otherwise nil is returned for them
+func (pt *PathTrie) nodeForPattern(pattern string, create bool) (*segmentNode, error) {
+	if len(pattern) == 0 {
+		return nil, errorf(ErrEmptyKey, "no pattern")
+	}
+
+	segments := splitPath(pattern)
+	n := pt.root
+	for i, segment := range segments {
+		var cNode **segmentNode
+		switch {
+		case strings.HasPrefix(segment, string(catchAllPrefix)):
+			if i != len(segments)-1 {
+				return nil, fmt.Errorf("catch-all %s must be the last segment", segment)
+			}
+			cNode = &n.catchAll
+		case strings.HasPrefix(segment, string(paramPrefix)):
+			cNode = &n.param
+		default:
+			child, ok := n.children[segment]
+			if !ok {
+				if !create {
+					return nil, nil
+				}
+				child = n.addChild(segment)
+				n.children[segment] = child
+			}
+			n = child
+			continue
+		}
+
+		if len(segment) == 1 {
+			return nil, fmt.Errorf("segment %s has no name", segment)
+		}
+		if *cNode == nil {
+			if !create {
+				return nil, nil
+			}
+			*cNode = n.addChild(segment)
+		}
+		if (*cNode).segment != segment {
+			return nil, fmt.Errorf("segment %s conflicts with %s", segment, (*cNode).segment)
+		}
+		n = *cNode
+	}
+
+	return n, nil
+}
+
+// addChild creates a child node for the segment. The caller links it into the right field
+func (sn *segmentNode) addChild(segment string) *segmentNode {
+	return &segmentNode{
+		segment:  segment,
+		parent:   sn,
+		children: make(map[string]*segmentNode),
+	}
+}
+
+// removeChild unlinks the child from whichever field of the node holds it
+func (sn *segmentNode) removeChild(child *segmentNode) {
+	switch child {
+	case sn.param:
+		sn.param = nil
+	case sn.catchAll:
+		sn.catchAll = nil
+	default:
+		delete(sn.children, child.segment)
+	}
+}
+
+// match returns the terminating node matching the segments beginning from the node, backtracking
+// from static to parameter to catch-all children. Captured parameters are added to params
+func (sn *segmentNode) match(segments []string, params Params) *segmentNode {
+	if len(segments) == 0 {
+		if sn.isTerm {
+			return sn
+		}
+		// A catch-all also matches an empty remainder
+		if sn.catchAll != nil && sn.catchAll.isTerm {
+			params[sn.catchAll.segment[1:]] = ""
+			return sn.catchAll
+		}
+		return nil
+	}
+
+	segment := segments[0]
+	if child, ok := sn.children[segment]; ok {
+		if n := child.match(segments[1:], params); n != nil {
+			return n
+		}
+	}
+
+	if sn.param != nil && len(segment) > 0 {
+		if n := sn.param.match(segments[1:], params); n != nil {
+			params[sn.param.segment[1:]] = segment
+			return n
+		}
+	}
+
+	if sn.catchAll != nil && sn.catchAll.isTerm {
+		params[sn.catchAll.segment[1:]] = strings.Join(segments, pathSeparator)
+		return sn.catchAll
+	}
+
+	return nil
+}
+
+// patterns adds the patterns at and below the node
+func (sn *segmentNode) patterns(patterns *[]string) {
+	if sn.isTerm {
+		*patterns = append(*patterns, sn.pattern)
+	}
+	for _, child := range sn.children {
+		child.patterns(patterns)
+	}
+	if sn.param != nil {
+		sn.param.patterns(patterns)
+	}
+	if sn.catchAll != nil {
+		sn.catchAll.patterns(patterns)
+	}
+}
+
+// splitPath splits a path into its segments ignoring the leading and trailing separators, so "/"
+// has no segments
+func splitPath(path string) []string {
+	path = strings.Trim(path, pathSeparator)
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, pathSeparator)
+}
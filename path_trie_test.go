@@ -0,0 +1,157 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathTrieLookup(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Path       string
+		ExpPattern string
+		ExpParams  Params
+		NotFound   bool
+	}{
+		{
+			Name:       "root path matches root pattern",
+			Path:       "/",
+			ExpPattern: "/",
+			ExpParams:  Params{},
+		},
+		{
+			Name:       "static segments match",
+			Path:       "/users/new",
+			ExpPattern: "/users/new",
+			ExpParams:  Params{},
+		},
+		{
+			Name:       "parameter captures a segment",
+			Path:       "/users/42",
+			ExpPattern: "/users/:id",
+			ExpParams:  Params{"id": "42"},
+		},
+		{
+			Name:       "parameters are captured at several depths",
+			Path:       "/users/42/posts/7",
+			ExpPattern: "/users/:id/posts/:post",
+			ExpParams:  Params{"id": "42", "post": "7"},
+		},
+		{
+			Name:       "static match backtracks to parameter",
+			Path:       "/users/new/posts/7",
+			ExpPattern: "/users/:id/posts/:post",
+			ExpParams:  Params{"id": "new", "post": "7"},
+		},
+		{
+			Name:       "catch-all captures the rest of the path",
+			Path:       "/static/css/site.css",
+			ExpPattern: "/static/*file",
+			ExpParams:  Params{"file": "css/site.css"},
+		},
+		{
+			Name:       "catch-all matches an empty rest",
+			Path:       "/static/",
+			ExpPattern: "/static/*file",
+			ExpParams:  Params{"file": ""},
+		},
+		{
+			Name:     "path without a pattern is not found",
+			Path:     "/users/42/comments",
+			NotFound: true,
+		},
+	}
+
+	pt := NewPathTrie()
+	for _, pattern := range []string{"/", "/users/new", "/users/:id", "/users/:id/posts/:post", "/static/*file"} {
+		require.Empty(t, pt.Add(pattern, pattern))
+	}
+
+	for _, test := range cases {
+		data, params, ok := pt.Lookup(test.Path)
+
+		if test.NotFound {
+			assert.False(t, ok, test.Name)
+			continue
+		}
+		require.True(t, ok, test.Name)
+		assert.Equal(t, test.ExpPattern, data, test.Name)
+		assert.Equal(t, test.ExpParams, params, test.Name)
+	}
+}
+
+func TestPathTrieAddRemove(t *testing.T) {
+	var cases = []struct {
+		Name        string
+		Op          string //add,remove
+		Pattern     string
+		ExpPatterns []string
+		ExpectErr   string
+	}{
+		{
+			Name:        "pattern is added",
+			Op:          "add",
+			Pattern:     "/users/:id/avatar",
+			ExpPatterns: []string{"/users/:id", "/files/*path", "/users/:id/avatar"},
+		},
+		{
+			Name:      "existing pattern throws error",
+			Op:        "add",
+			Pattern:   "/users/:id",
+			ExpectErr: "pattern /users/:id already exists",
+		},
+		{
+			Name:      "conflicting parameter name throws error",
+			Op:        "add",
+			Pattern:   "/users/:name",
+			ExpectErr: "segment :name conflicts with :id",
+		},
+		{
+			Name:      "catch-all before the last segment throws error",
+			Op:        "add",
+			Pattern:   "/files/*path/edit",
+			ExpectErr: "catch-all *path must be the last segment",
+		},
+		{
+			Name:      "unnamed parameter throws error",
+			Op:        "add",
+			Pattern:   "/users/:",
+			ExpectErr: "segment : has no name",
+		},
+		{
+			Name:        "pattern is removed",
+			Op:          "remove",
+			Pattern:     "/users/:id",
+			ExpPatterns: []string{"/files/*path"},
+		},
+		{
+			Name:      "missing pattern throws error",
+			Op:        "remove",
+			Pattern:   "/users",
+			ExpectErr: "could not find pattern /users",
+		},
+	}
+
+	for _, test := range cases {
+		pt := NewPathTrie()
+		require.Empty(t, pt.Add("/users/:id", nil), test.Name)
+		require.Empty(t, pt.Add("/files/*path", nil), test.Name)
+
+		var err error
+		if test.Op == "add" {
+			err = pt.Add(test.Pattern, nil)
+		} else if test.Op == "remove" {
+			err = pt.Remove(test.Pattern)
+		}
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpPatterns, pt.Patterns(), test.Name)
+	}
+}
@@ -0,0 +1,91 @@
+package trie
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+)
+
+// snapshot is the gob encoded form of a trie written by Save
+type snapshot struct {
+	Name    string
+	Form    Normalization
+	Fold    bool
+	Strip   bool
+	Suffix  bool
+	Entries []snapshotEntry
+}
+
+// snapshotEntry is a word of a snapshot as it was added along with its data
+type snapshotEntry struct {
+	Word string
+	Data interface{}
+}
+
+// Save writes the trie to w, including its key mode and suffix index option. Data is gob encoded, so
+// data types other than the basic ones must be registered with gob.Register
+func (t *Trie) Save(w io.Writer) error {
+	snap := snapshot{
+		Name:    t.Name,
+		Suffix:  t.suffix != nil,
+		Entries: []snapshotEntry{},
+	}
+	if t.keyMode != nil {
+		snap.Form = t.keyMode.form
+		snap.Fold = t.keyMode.fold
+		snap.Strip = t.keyMode.strip
+	}
+
+	t.walk(func(path []rune, n Node) WalkAction {
+		if n.IsTerminal() && !t.expiredPath(path) {
+			snap.Entries = append(snap.Entries, snapshotEntry{
+				Word: t.spelling(string(path)),
+				Data: n.Data(),
+			})
+		}
+		return Continue
+	}, SortedChildren())
+
+	if err := gob.NewEncoder(w).Encode(snap); err != nil {
+		return fmt.Errorf("could not save trie %s: %s", t.Name, err)
+	}
+
+	return nil
+}
+
+// Load reads a trie written by Save from r
+func Load(r io.Reader) (*Trie, error) {
+	return load(r)
+}
+
+// load reads a trie written by Save from r like Load, applying extra after the options saved with the
+// trie
+func load(r io.Reader, extra ...Option) (*Trie, error) {
+	snap := snapshot{}
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return nil, fmt.Errorf("could not load trie: %s", err)
+	}
+
+	opts := []Option{}
+	if snap.Form != 0 {
+		opts = append(opts, WithNormalization(snap.Form))
+	}
+	if snap.Fold {
+		opts = append(opts, WithCaseFolding())
+	}
+	if snap.Strip {
+		opts = append(opts, WithDiacriticStripping())
+	}
+	if snap.Suffix {
+		opts = append(opts, WithSuffixIndex())
+	}
+
+	tr := New(snap.Name, append(opts, extra...)...)
+	for _, entry := range snap.Entries {
+		if _, err := tr.add(entry.Word, entry.Data); err != nil {
+			return nil, fmt.Errorf("could not load word %s: %s", entry.Word, err)
+		}
+	}
+
+	return tr, nil
+}
@@ -0,0 +1,63 @@
+package trie
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoad(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Opts      []Option
+		Input     string
+		ExpectErr string
+	}{
+		{
+			Name: "plain trie is saved and loaded",
+		},
+		{
+			Name: "key modes and suffix index are saved and loaded",
+			Opts: []Option{WithNormalization(NFC), WithCaseFolding(), WithSuffixIndex()},
+		},
+		{
+			Name:      "garbage input throws error",
+			Input:     "not a trie",
+			ExpectErr: "could not load trie",
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test", test.Opts...)
+		for word, data := range map[string]interface{}{"Car": 1, "cart": "two", "dog": nil} {
+			_, err := tr.Add(word, data)
+			require.Empty(t, err, test.Name)
+		}
+
+		buf := &bytes.Buffer{}
+		require.Empty(t, tr.Save(buf), test.Name)
+		if test.Input != "" {
+			buf = bytes.NewBufferString(test.Input)
+		}
+
+		loaded, err := Load(buf)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		require.Empty(t, err, test.Name)
+		assert.Equal(t, "test", loaded.Name, test.Name)
+		assert.Equal(t, Patch{}, Diff(tr, loaded), test.Name)
+		assert.ElementsMatch(t, tr.Words(), loaded.Words(), test.Name)
+		assert.Equal(t, tr.keyMode, loaded.keyMode, test.Name)
+		assert.Equal(t, tr.suffix != nil, loaded.suffix != nil, test.Name)
+	}
+
+	_, err := Load(strings.NewReader(""))
+	require.NotEmpty(t, err, "empty input should throw error")
+}
@@ -0,0 +1,190 @@
+package trie
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// Runes used to key a route table. Every prefix starts with its address family followed by one
+// rune per bit of the prefix, so IPv4 and IPv6 routes live in separate branches of one trie
+const (
+	familyV4 = '4'
+	familyV6 = '6'
+	bitZero  = '0'
+	bitOne   = '1'
+)
+
+// Route defines a prefix in a route table with the value stored for it
+type Route struct {
+	Prefix netip.Prefix
+	Value  interface{}
+}
+
+// RouteTable defines a routing table of IPv4 and IPv6 prefixes stored in a bitwise trie, which
+// gives longest prefix matching of addresses
+type RouteTable struct {
+	trie *Trie
+}
+
+// NewRouteTable creates an empty route table
+func NewRouteTable() *RouteTable {
+	return &RouteTable{
+		trie: New("RouteTable"),
+	}
+}
+
+// Insert adds the prefix with value to the table. The prefix is masked first so that host bits
+// are ignored, and an IPv4-mapped IPv6 prefix is stored as its IPv4 prefix. If the prefix is
+// already in the table its value is replaced
+func (rt *RouteTable) Insert(prefix netip.Prefix, value interface{}) error {
+	prefix, ok := normalizePrefix(prefix)
+	if !ok {
+		return fmt.Errorf("invalid prefix %s", prefix)
+	}
+
+	termNode := rt.trie.addPath(rt.trie.root(), prefixRunes(prefix))
+	rt.trie.setTerm(termNode, true)
+	rt.trie.setData(termNode, Route{
+		Prefix: prefix,
+		Value:  value,
+	})
+
+	return nil
+}
+
+// Delete removes the prefix from the table. An error is returned if the prefix is not in the table
+func (rt *RouteTable) Delete(prefix netip.Prefix) error {
+	prefix, ok := normalizePrefix(prefix)
+	if !ok {
+		return fmt.Errorf("invalid prefix %s", prefix)
+	}
+
+	termNode, err := rt.trie.findAtNode(rt.trie.root(), prefixRunes(prefix), 0)
+	if err != nil {
+		return errorf(ErrNotFound, "prefix %s not found in route table", prefix)
+	}
+
+	rt.trie.unterminate(termNode)
+
+	return nil
+}
+
+// Lookup returns the most specific route containing the address. The bool is false if no route
+// contains the address
+func (rt *RouteTable) Lookup(addr netip.Addr) (Route, bool) {
+	if !addr.IsValid() {
+		return Route{}, false
+	}
+
+	addr = addr.Unmap()
+	termNode, _ := rt.trie.longestPrefixAtNode(rt.trie.root(), prefixRunes(netip.PrefixFrom(addr, addr.BitLen())))
+	if termNode == nil {
+		return Route{}, false
+	}
+
+	return termNode.Data().(Route), true
+}
+
+// Covering returns the routes which contain the prefix, including the prefix itself, from the least
+// to the most specific
+func (rt *RouteTable) Covering(prefix netip.Prefix) []Route {
+	routes := []Route{}
+	prefix, ok := normalizePrefix(prefix)
+	if !ok {
+		return routes
+	}
+
+	n := rt.trie.root()
+	for _, r := range prefixRunes(prefix) {
+		cNode, ok := n.Child(r)
+		if !ok {
+			break
+		}
+		if cNode.IsTerminal() {
+			routes = append(routes, cNode.Data().(Route))
+		}
+		n = cNode
+	}
+
+	return routes
+}
+
+// Covered returns the routes contained in the prefix, including the prefix itself, ordered by
+// address and then by prefix length
+func (rt *RouteTable) Covered(prefix netip.Prefix) []Route {
+	routes := []Route{}
+	prefix, ok := normalizePrefix(prefix)
+	if !ok {
+		return routes
+	}
+
+	if n := rt.trie.nodeAtPrefix(prefixRunes(prefix)); n != nil {
+		routesAtNode(n, &routes)
+	}
+
+	return routes
+}
+
+// Routes returns all routes in the table, IPv4 before IPv6, ordered by address and then by prefix
+// length
+func (rt *RouteTable) Routes() []Route {
+	routes := []Route{}
+
+	for _, family := range []rune{familyV4, familyV6} {
+		if n, ok := rt.trie.root().Child(family); ok {
+			routesAtNode(n, &routes)
+		}
+	}
+
+	return routes
+}
+
+// routesAtNode adds the routes at and below the node specified, visiting the zero bit before the
+// one bit
+func routesAtNode(n Node, routes *[]Route) {
+	if n.IsTerminal() {
+		*routes = append(*routes, n.Data().(Route))
+	}
+
+	for _, r := range []rune{bitZero, bitOne} {
+		if cNode, ok := n.Child(r); ok {
+			routesAtNode(cNode, routes)
+		}
+	}
+}
+
+// normalizePrefix gives the prefix as the table keys it, masked and with an IPv4-mapped IPv6 prefix
+// turned into its IPv4 prefix as Lookup unmaps addresses. False is returned if the prefix is invalid
+func normalizePrefix(prefix netip.Prefix) (netip.Prefix, bool) {
+	if !prefix.IsValid() {
+		return prefix, false
+	}
+
+	// Mapped prefixes shorter than the mapping itself cover more than IPv4 and stay IPv6
+	if addr := prefix.Addr(); addr.Is4In6() && prefix.Bits() >= 96 {
+		prefix = netip.PrefixFrom(addr.Unmap(), prefix.Bits()-96)
+	}
+
+	return prefix.Masked(), true
+}
+
+// prefixRunes gives the runes keying the prefix in the route table
+func prefixRunes(prefix netip.Prefix) []rune {
+	runes := make([]rune, 0, prefix.Bits()+1)
+	if prefix.Addr().Is4() {
+		runes = append(runes, familyV4)
+	} else {
+		runes = append(runes, familyV6)
+	}
+
+	addr := prefix.Addr().AsSlice()
+	for i := 0; i < prefix.Bits(); i++ {
+		if addr[i/8]&(0x80>>(i%8)) != 0 {
+			runes = append(runes, bitOne)
+		} else {
+			runes = append(runes, bitZero)
+		}
+	}
+
+	return runes
+}
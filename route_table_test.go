@@ -0,0 +1,242 @@
+package trie
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouteTable(t *testing.T) *RouteTable {
+	rt := NewRouteTable()
+	for _, p := range []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "192.168.0.0/16", "2001:db8::/32", "2001:db8:1::/48"} {
+		require.Empty(t, rt.Insert(netip.MustParsePrefix(p), p))
+	}
+	return rt
+}
+
+func TestRouteTableLookup(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Addr     string
+		ExpRoute string
+		NotFound bool
+	}{
+		{
+			Name:     "most specific IPv4 route is returned",
+			Addr:     "10.1.2.3",
+			ExpRoute: "10.1.2.0/24",
+		},
+		{
+			Name:     "less specific IPv4 route is returned when no longer route matches",
+			Addr:     "10.1.3.3",
+			ExpRoute: "10.1.0.0/16",
+		},
+		{
+			Name:     "default route is returned when nothing else matches",
+			Addr:     "172.16.0.1",
+			ExpRoute: "0.0.0.0/0",
+		},
+		{
+			Name:     "IPv4 mapped IPv6 address matches IPv4 routes",
+			Addr:     "::ffff:192.168.1.1",
+			ExpRoute: "192.168.0.0/16",
+		},
+		{
+			Name:     "most specific IPv6 route is returned",
+			Addr:     "2001:db8:1::1",
+			ExpRoute: "2001:db8:1::/48",
+		},
+		{
+			Name:     "IPv6 address without route is not found",
+			Addr:     "2001:db9::1",
+			NotFound: true,
+		},
+	}
+
+	rt := newTestRouteTable(t)
+
+	for _, test := range cases {
+		route, ok := rt.Lookup(netip.MustParseAddr(test.Addr))
+
+		if test.NotFound {
+			assert.False(t, ok, test.Name)
+			continue
+		}
+		require.True(t, ok, test.Name)
+		assert.Equal(t, netip.MustParsePrefix(test.ExpRoute), route.Prefix, test.Name)
+		assert.Equal(t, test.ExpRoute, route.Value, test.Name)
+	}
+}
+
+func TestRouteTableInsertDelete(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Op        string //insert,delete
+		Prefix    string
+		Addr      string
+		ExpRoute  string
+		ExpValue  string
+		ExpectErr string
+	}{
+		{
+			Name:     "host bits are masked on insert",
+			Op:       "insert",
+			Prefix:   "10.1.2.200/25",
+			Addr:     "10.1.2.201",
+			ExpRoute: "10.1.2.128/25",
+			ExpValue: "10.1.2.200/25",
+		},
+		{
+			Name:     "existing prefix value is replaced",
+			Op:       "insert",
+			Prefix:   "10.1.0.0/16",
+			Addr:     "10.1.3.1",
+			ExpRoute: "10.1.0.0/16",
+			ExpValue: "10.1.0.0/16",
+		},
+		{
+			Name:     "deleted prefix falls back to the covering route",
+			Op:       "delete",
+			Prefix:   "10.1.0.0/16",
+			Addr:     "10.1.3.1",
+			ExpRoute: "10.0.0.0/8",
+			ExpValue: "10.0.0.0/8",
+		},
+		{
+			Name:     "deleting a route keeps the more specific routes below it",
+			Op:       "delete",
+			Prefix:   "10.1.0.0/16",
+			Addr:     "10.1.2.1",
+			ExpRoute: "10.1.2.0/24",
+			ExpValue: "10.1.2.0/24",
+		},
+		{
+			Name:     "IPv4 mapped prefix is inserted as IPv4",
+			Op:       "insert",
+			Prefix:   "::ffff:10.9.0.0/112",
+			Addr:     "10.9.0.1",
+			ExpRoute: "10.9.0.0/16",
+			ExpValue: "::ffff:10.9.0.0/112",
+		},
+		{
+			Name:     "IPv4 mapped prefix deletes its IPv4 route",
+			Op:       "delete",
+			Prefix:   "::ffff:10.1.0.0/112",
+			Addr:     "::ffff:10.1.3.1",
+			ExpRoute: "10.0.0.0/8",
+			ExpValue: "10.0.0.0/8",
+		},
+		{
+			Name:      "deleting a missing prefix throws error",
+			Op:        "delete",
+			Prefix:    "10.2.0.0/16",
+			ExpectErr: "prefix 10.2.0.0/16 not found",
+		},
+		{
+			Name:      "short IPv4 mapped prefix stays IPv6",
+			Op:        "delete",
+			Prefix:    "::ffff:0.0.0.0/90",
+			ExpectErr: "prefix ::ffc0:0:0/90 not found",
+		},
+	}
+
+	for _, test := range cases {
+		rt := newTestRouteTable(t)
+		prefix := netip.MustParsePrefix(test.Prefix)
+
+		var err error
+		if test.Op == "insert" {
+			err = rt.Insert(prefix, test.Prefix)
+		} else if test.Op == "delete" {
+			err = rt.Delete(prefix)
+		}
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+
+		route, ok := rt.Lookup(netip.MustParseAddr(test.Addr))
+		require.True(t, ok, test.Name)
+		assert.Equal(t, netip.MustParsePrefix(test.ExpRoute), route.Prefix, test.Name)
+		assert.Equal(t, test.ExpValue, route.Value, test.Name)
+	}
+
+	err := NewRouteTable().Insert(netip.Prefix{}, nil)
+	require.NotEmpty(t, err, "invalid prefix should throw error")
+	assert.Contains(t, err.Error(), "invalid prefix")
+}
+
+func TestRouteTableEnumerate(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Fun       string //covering,covered,routes
+		Prefix    string
+		ExpRoutes []string
+	}{
+		{
+			Name:      "covering routes are returned from least to most specific",
+			Fun:       "covering",
+			Prefix:    "10.1.2.128/25",
+			ExpRoutes: []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"},
+		},
+		{
+			Name:      "covered routes are returned in order",
+			Fun:       "covered",
+			Prefix:    "10.0.0.0/8",
+			ExpRoutes: []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"},
+		},
+		{
+			Name:      "covering routes of IPv4 mapped prefix are IPv4 routes",
+			Fun:       "covering",
+			Prefix:    "::ffff:10.1.2.128/121",
+			ExpRoutes: []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"},
+		},
+		{
+			Name:      "covered routes of IPv4 mapped prefix are IPv4 routes",
+			Fun:       "covered",
+			Prefix:    "::ffff:10.0.0.0/104",
+			ExpRoutes: []string{"10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24"},
+		},
+		{
+			Name:      "covered IPv6 routes are returned",
+			Fun:       "covered",
+			Prefix:    "2001:db8::/16",
+			ExpRoutes: []string{"2001:db8::/32", "2001:db8:1::/48"},
+		},
+		{
+			Name:      "prefix without covered routes returns none",
+			Fun:       "covered",
+			Prefix:    "172.16.0.0/12",
+			ExpRoutes: []string{},
+		},
+		{
+			Name:      "all routes are returned with IPv4 first",
+			Fun:       "routes",
+			ExpRoutes: []string{"0.0.0.0/0", "10.0.0.0/8", "10.1.0.0/16", "10.1.2.0/24", "192.168.0.0/16", "2001:db8::/32", "2001:db8:1::/48"},
+		},
+	}
+
+	rt := newTestRouteTable(t)
+
+	for _, test := range cases {
+		var routes []Route
+		if test.Fun == "covering" {
+			routes = rt.Covering(netip.MustParsePrefix(test.Prefix))
+		} else if test.Fun == "covered" {
+			routes = rt.Covered(netip.MustParsePrefix(test.Prefix))
+		} else if test.Fun == "routes" {
+			routes = rt.Routes()
+		}
+
+		prefixes := []string{}
+		for _, route := range routes {
+			prefixes = append(prefixes, route.Prefix.String())
+		}
+		assert.Equal(t, test.ExpRoutes, prefixes, test.Name)
+	}
+}
@@ -0,0 +1,97 @@
+package trie
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// paramsKey is the context key under which a Router stores the parameters of the matched pattern
+type paramsKey struct{}
+
+// Router defines an http.Handler which dispatches requests on the path patterns of a PathTrie and
+// then on the request method
+type Router struct {
+	routes *PathTrie
+
+	// NotFound handles requests whose path matches no pattern. http.NotFound is used if it is nil
+	NotFound http.Handler
+}
+
+// NewRouter creates a router with no routes
+func NewRouter() *Router {
+	return &Router{
+		routes: NewPathTrie(),
+	}
+}
+
+// Handle registers the handler for the method and path pattern. An error is returned if the
+// pattern is malformed or the method is already registered for it
+func (rt *Router) Handle(method string, pattern string, handler http.Handler) error {
+	if method == "" {
+		return fmt.Errorf("no method for pattern %s", pattern)
+	}
+
+	data, err := rt.routes.Find(pattern)
+	if err != nil {
+		data = map[string]http.Handler{}
+		if err := rt.routes.Add(pattern, data); err != nil {
+			return err
+		}
+	}
+
+	handlers := data.(map[string]http.Handler)
+	if _, ok := handlers[method]; ok {
+		return errorf(ErrExists, "method %s already registered for pattern %s", method, pattern)
+	}
+	handlers[method] = handler
+
+	return nil
+}
+
+// HandleFunc registers the handler function for the method and path pattern
+func (rt *Router) HandleFunc(method string, pattern string, handler http.HandlerFunc) error {
+	return rt.Handle(method, pattern, handler)
+}
+
+// ServeHTTP dispatches the request to the handler registered for its path and method. The captured
+// parameters are available to the handler through PathParams. Requests whose path matches but whose
+// method does not are answered with 405 and an Allow header. HEAD falls back to GET
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, params, ok := rt.routes.Lookup(r.URL.Path)
+	if !ok {
+		if rt.NotFound != nil {
+			rt.NotFound.ServeHTTP(w, r)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	handlers := data.(map[string]http.Handler)
+	handler, ok := handlers[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		handler, ok = handlers[http.MethodGet]
+	}
+	if !ok {
+		methods := make([]string, 0, len(handlers))
+		for method := range handlers {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	handler.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), paramsKey{}, params)))
+}
+
+// PathParams returns the parameters captured by the pattern which matched the request. It returns
+// nil for requests which were not dispatched by a Router
+func PathParams(r *http.Request) Params {
+	params, _ := r.Context().Value(paramsKey{}).(Params)
+	return params
+}
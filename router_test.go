@@ -0,0 +1,80 @@
+package trie
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Method   string
+		Path     string
+		ExpCode  int
+		ExpBody  string
+		ExpAllow string
+	}{
+		{
+			Name:    "request is dispatched with params",
+			Method:  http.MethodGet,
+			Path:    "/users/42",
+			ExpCode: http.StatusOK,
+			ExpBody: "get user 42",
+		},
+		{
+			Name:    "request is dispatched on method",
+			Method:  http.MethodDelete,
+			Path:    "/users/42",
+			ExpCode: http.StatusOK,
+			ExpBody: "delete user 42",
+		},
+		{
+			Name:    "head falls back to get",
+			Method:  http.MethodHead,
+			Path:    "/users/42",
+			ExpCode: http.StatusOK,
+		},
+		{
+			Name:     "unregistered method is not allowed",
+			Method:   http.MethodPost,
+			Path:     "/users/42",
+			ExpCode:  http.StatusMethodNotAllowed,
+			ExpAllow: "DELETE, GET",
+		},
+		{
+			Name:    "unknown path is not found",
+			Method:  http.MethodGet,
+			Path:    "/teams/1",
+			ExpCode: http.StatusNotFound,
+		},
+	}
+
+	rt := NewRouter()
+	require.Empty(t, rt.HandleFunc(http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "get user %s", PathParams(r)["id"])
+	}))
+	require.Empty(t, rt.HandleFunc(http.MethodDelete, "/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "delete user %s", PathParams(r)["id"])
+	}))
+	err := rt.HandleFunc(http.MethodGet, "/users/:id", func(w http.ResponseWriter, r *http.Request) {})
+	require.NotEmpty(t, err, "registering a method twice should throw error")
+	assert.Contains(t, err.Error(), "method GET already registered")
+
+	for _, test := range cases {
+		rec := httptest.NewRecorder()
+		rt.ServeHTTP(rec, httptest.NewRequest(test.Method, test.Path, nil))
+
+		assert.Equal(t, test.ExpCode, rec.Code, test.Name)
+		if test.ExpBody != "" {
+			assert.Equal(t, test.ExpBody, rec.Body.String(), test.Name)
+		}
+		if test.ExpAllow != "" {
+			assert.Equal(t, test.ExpAllow, rec.Header().Get("Allow"), test.Name)
+		}
+	}
+}
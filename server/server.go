@@ -0,0 +1,307 @@
+// Package server exposes named tries over HTTP as a JSON autocomplete service
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	trie "example.com/compact-trie"
+)
+
+const defaultLimit = 10
+const defaultMaxLimit = 1000
+
+// Options configures a server
+type Options struct {
+	// AdminToken must be sent as a bearer token to the admin endpoints. The admin endpoints are
+	// disabled if it is empty
+	AdminToken string
+	// MaxLimit caps the number of results a query can ask for. 1000 is used if it is zero
+	MaxLimit int
+}
+
+// Server serves lookups, completions and suggestions from named tries. It is safe for concurrent
+// use, with admin updates serialized against queries
+type Server struct {
+	mu     sync.RWMutex
+	tries  map[string]*trie.Trie
+	opts   Options
+	router *trie.Router
+}
+
+// entry is the JSON form of a word and its data
+type entry struct {
+	Word     string      `json:"word"`
+	Data     interface{} `json:"data,omitempty"`
+	Distance *int        `json:"distance,omitempty"`
+}
+
+// New creates a server for the tries, keyed by the name used in the URLs
+func New(tries map[string]*trie.Trie, opts Options) *Server {
+	if opts.MaxLimit == 0 {
+		opts.MaxLimit = defaultMaxLimit
+	}
+
+	s := &Server{
+		tries:  map[string]*trie.Trie{},
+		opts:   opts,
+		router: trie.NewRouter(),
+	}
+	for name, tr := range tries {
+		s.tries[name] = tr
+	}
+
+	s.router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "no endpoint %s", r.URL.Path)
+	})
+
+	// The patterns are fixed so registering them cannot fail
+	s.router.HandleFunc(http.MethodGet, "/tries", s.handleList)
+	s.router.HandleFunc(http.MethodGet, "/tries/:name/find", s.query(s.handleFind))
+	s.router.HandleFunc(http.MethodGet, "/tries/:name/complete", s.query(s.handleComplete))
+	s.router.HandleFunc(http.MethodGet, "/tries/:name/top", s.query(s.handleTop))
+	s.router.HandleFunc(http.MethodGet, "/tries/:name/fuzzy", s.query(s.handleFuzzy))
+	s.router.HandleFunc(http.MethodPost, "/admin/tries/:name/words", s.admin(s.handleAdd))
+	s.router.HandleFunc(http.MethodDelete, "/admin/tries/:name/words", s.admin(s.handleRemove))
+
+	return s
+}
+
+// ServeHTTP serves the endpoints of the server
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// trieHandler handles a request for the trie named in its path
+type trieHandler func(w http.ResponseWriter, r *http.Request, tr *trie.Trie)
+
+// query wraps a read-only handler, resolving the trie under a read lock
+func (s *Server) query(h trieHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+
+		if tr, ok := s.trie(w, r); ok {
+			h(w, r, tr)
+		}
+	}
+}
+
+// admin wraps a handler which changes a trie, checking the admin token and resolving the trie
+// under a write lock
+func (s *Server) admin(h trieHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.opts.AdminToken == "" {
+			writeError(w, http.StatusForbidden, "admin endpoints are disabled")
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer "+s.opts.AdminToken {
+			writeError(w, http.StatusUnauthorized, "invalid admin token")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if tr, ok := s.trie(w, r); ok {
+			h(w, r, tr)
+		}
+	}
+}
+
+// trie gives the trie named in the path of the request, answering 404 if there is none
+func (s *Server) trie(w http.ResponseWriter, r *http.Request) (*trie.Trie, bool) {
+	name := trie.PathParams(r)["name"]
+	tr, ok := s.tries[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "no trie named %s", name)
+	}
+	return tr, ok
+}
+
+// handleList lists the names of the tries
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.tries))
+	for name := range s.tries {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+
+	sort.Strings(names)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tries": names})
+}
+
+// handleFind looks up the word parameter exactly
+func (s *Server) handleFind(w http.ResponseWriter, r *http.Request, tr *trie.Trie) {
+	word := r.URL.Query().Get("word")
+	n, err := tr.Find(word)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "word %s not found", word)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, entry{Word: word, Data: n.Data()})
+}
+
+// handleComplete lists the words beginning with the prefix parameter in sorted order
+func (s *Server) handleComplete(w http.ResponseWriter, r *http.Request, tr *trie.Trie) {
+	limit, ok := s.limit(w, r, "limit")
+	if !ok {
+		return
+	}
+
+	words := tr.WordsWithPrefix(r.URL.Query().Get("prefix"))
+	sort.Strings(words)
+	if len(words) > limit {
+		words = words[:limit]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"words": words})
+}
+
+// handleTop lists the k words beginning with the prefix parameter which have the highest numeric
+// data. Words whose data is not a number score zero and ties are broken by word
+func (s *Server) handleTop(w http.ResponseWriter, r *http.Request, tr *trie.Trie) {
+	k, ok := s.limit(w, r, "k")
+	if !ok {
+		return
+	}
+
+	// Reading the data of the words is part of the same use as listing them
+	entries := []entry{}
+	scores := map[string]float64{}
+	for _, word := range tr.WordsWithPrefix(r.URL.Query().Get("prefix")) {
+		n, err := tr.Peek(word)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{Word: word, Data: n.Data()})
+		scores[word] = score(n.Data())
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		si, sj := scores[entries[i].Word], scores[entries[j].Word]
+		if si != sj {
+			return si > sj
+		}
+		return entries[i].Word < entries[j].Word
+	})
+	if len(entries) > k {
+		entries = entries[:k]
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"words": entries})
+}
+
+// handleFuzzy lists the words within the distance parameter of the word parameter, closest first
+func (s *Server) handleFuzzy(w http.ResponseWriter, r *http.Request, tr *trie.Trie) {
+	limit, ok := s.limit(w, r, "limit")
+	if !ok {
+		return
+	}
+	dist := 1
+	if v := r.URL.Query().Get("distance"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 0 {
+			writeError(w, http.StatusBadRequest, "invalid distance %s", v)
+			return
+		}
+		dist = d
+	}
+
+	entries := []entry{}
+	for _, match := range tr.FuzzyFind(r.URL.Query().Get("word"), dist) {
+		if len(entries) == limit {
+			break
+		}
+		distance := match.Distance
+		entries = append(entries, entry{Word: match.Word, Data: match.Data, Distance: &distance})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"words": entries})
+}
+
+// handleAdd adds the word in the JSON body to the trie
+func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request, tr *trie.Trie) {
+	e := entry{}
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid body: %s", err)
+		return
+	}
+	if _, err := tr.Add(e.Word, e.Data); err != nil {
+		code := http.StatusConflict
+		if errors.Is(err, trie.ErrEmptyKey) {
+			code = http.StatusBadRequest
+		}
+		writeError(w, code, "could not add word %s: %s", e.Word, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, entry{Word: e.Word, Data: e.Data})
+}
+
+// handleRemove removes the word parameter from the trie. The word is a query parameter so it can
+// contain any character, including slashes
+func (s *Server) handleRemove(w http.ResponseWriter, r *http.Request, tr *trie.Trie) {
+	word := r.URL.Query().Get("word")
+	if word == "" {
+		writeError(w, http.StatusBadRequest, "no word to remove")
+		return
+	}
+	if err := tr.Remove(word); err != nil {
+		writeError(w, http.StatusNotFound, "word %s not found", word)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// limit parses the query parameter as a result limit, answering 400 if it is invalid
+func (s *Server) limit(w http.ResponseWriter, r *http.Request, param string) (int, bool) {
+	v := r.URL.Query().Get(param)
+	if v == "" {
+		return min(defaultLimit, s.opts.MaxLimit), true
+	}
+
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid %s %s", param, v)
+		return 0, false
+	}
+
+	return min(limit, s.opts.MaxLimit), true
+}
+
+// score gives the ranking score of the data of a word
+func score(data interface{}) float64 {
+	switch v := data.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return 0
+}
+
+// writeJSON writes the value as the JSON response
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the formatted message as a JSON error response
+func writeError(w http.ResponseWriter, code int, format string, args ...interface{}) {
+	writeJSON(w, code, map[string]string{"error": fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	trie "example.com/compact-trie"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestServer(t *testing.T) *Server {
+	tr := trie.New("words")
+	for word, data := range map[string]interface{}{"car": "3", "cart": "10", "care": "7", "cat": nil, "dog": "1"} {
+		_, err := tr.Add(word, data)
+		require.Empty(t, err)
+	}
+	return New(map[string]*trie.Trie{"words": tr}, Options{AdminToken: "secret", MaxLimit: 3})
+}
+
+func TestServerQueries(t *testing.T) {
+	var cases = []struct {
+		Name    string
+		Path    string
+		ExpCode int
+		ExpBody string
+	}{
+		{
+			Name:    "tries are listed",
+			Path:    "/tries",
+			ExpCode: http.StatusOK,
+			ExpBody: `{"tries":["words"]}`,
+		},
+		{
+			Name:    "word is found with data",
+			Path:    "/tries/words/find?word=cart",
+			ExpCode: http.StatusOK,
+			ExpBody: `{"word":"cart","data":"10"}`,
+		},
+		{
+			Name:    "missing word is not found",
+			Path:    "/tries/words/find?word=cow",
+			ExpCode: http.StatusNotFound,
+			ExpBody: `{"error":"word cow not found"}`,
+		},
+		{
+			Name:    "missing trie is not found",
+			Path:    "/tries/names/find?word=cart",
+			ExpCode: http.StatusNotFound,
+			ExpBody: `{"error":"no trie named names"}`,
+		},
+		{
+			Name:    "completions are sorted and limited",
+			Path:    "/tries/words/complete?prefix=ca&limit=2",
+			ExpCode: http.StatusOK,
+			ExpBody: `{"words":["car","care"]}`,
+		},
+		{
+			Name:    "limit is capped",
+			Path:    "/tries/words/complete?prefix=ca&limit=100",
+			ExpCode: http.StatusOK,
+			ExpBody: `{"words":["car","care","cart"]}`,
+		},
+		{
+			Name:    "invalid limit is rejected",
+			Path:    "/tries/words/complete?prefix=ca&limit=-1",
+			ExpCode: http.StatusBadRequest,
+			ExpBody: `{"error":"invalid limit -1"}`,
+		},
+		{
+			Name:    "top words are ranked by data",
+			Path:    "/tries/words/top?prefix=ca&k=2",
+			ExpCode: http.StatusOK,
+			ExpBody: `{"words":[{"word":"cart","data":"10"},{"word":"care","data":"7"}]}`,
+		},
+		{
+			Name:    "fuzzy suggestions carry their distance",
+			Path:    "/tries/words/fuzzy?word=cas&distance=1&limit=2",
+			ExpCode: http.StatusOK,
+			ExpBody: `{"words":[{"word":"car","data":"3","distance":1},{"word":"cat","distance":1}]}`,
+		},
+		{
+			Name:    "unknown endpoint is not found",
+			Path:    "/words",
+			ExpCode: http.StatusNotFound,
+			ExpBody: `{"error":"no endpoint /words"}`,
+		},
+	}
+
+	srv := newTestServer(t)
+
+	for _, test := range cases {
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.Path, nil))
+
+		assert.Equal(t, test.ExpCode, rec.Code, test.Name)
+		assert.JSONEq(t, test.ExpBody, rec.Body.String(), test.Name)
+	}
+}
+
+func TestServerAdmin(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Method   string
+		Path     string
+		Body     string
+		Token    string
+		NoAdmin  bool
+		ExpCode  int
+		ExpWords []string
+	}{
+		{
+			Name:     "word is added",
+			Method:   http.MethodPost,
+			Path:     "/admin/tries/words/words",
+			Body:     `{"word":"cab","data":"2"}`,
+			Token:    "secret",
+			ExpCode:  http.StatusCreated,
+			ExpWords: []string{"cab", "car", "care"},
+		},
+		{
+			Name:    "existing word conflicts",
+			Method:  http.MethodPost,
+			Path:    "/admin/tries/words/words",
+			Body:    `{"word":"car"}`,
+			Token:   "secret",
+			ExpCode: http.StatusConflict,
+		},
+		{
+			Name:    "empty word is rejected",
+			Method:  http.MethodPost,
+			Path:    "/admin/tries/words/words",
+			Body:    `{"word":""}`,
+			Token:   "secret",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Name:    "invalid body is rejected",
+			Method:  http.MethodPost,
+			Path:    "/admin/tries/words/words",
+			Body:    `{"word":`,
+			Token:   "secret",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Name:     "word is removed",
+			Method:   http.MethodDelete,
+			Path:     "/admin/tries/words/words?word=car",
+			Token:    "secret",
+			ExpCode:  http.StatusNoContent,
+			ExpWords: []string{"care", "cart", "cat"},
+		},
+		{
+			Name:    "removing a missing word is not found",
+			Method:  http.MethodDelete,
+			Path:    "/admin/tries/words/words?word=cow",
+			Token:   "secret",
+			ExpCode: http.StatusNotFound,
+		},
+		{
+			Name:    "removing no word is rejected",
+			Method:  http.MethodDelete,
+			Path:    "/admin/tries/words/words",
+			Token:   "secret",
+			ExpCode: http.StatusBadRequest,
+		},
+		{
+			Name:    "wrong token is unauthorized",
+			Method:  http.MethodDelete,
+			Path:    "/admin/tries/words/words?word=car",
+			Token:   "guess",
+			ExpCode: http.StatusUnauthorized,
+		},
+		{
+			Name:    "admin endpoints are disabled without a token",
+			Method:  http.MethodDelete,
+			Path:    "/admin/tries/words/words?word=car",
+			NoAdmin: true,
+			ExpCode: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range cases {
+		srv := newTestServer(t)
+		if test.NoAdmin {
+			srv.opts.AdminToken = ""
+		}
+
+		req := httptest.NewRequest(test.Method, test.Path, strings.NewReader(test.Body))
+		req.Header.Set("Authorization", "Bearer "+test.Token)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+
+		assert.Equal(t, test.ExpCode, rec.Code, test.Name+": "+rec.Body.String())
+		if test.ExpWords == nil {
+			continue
+		}
+
+		rec = httptest.NewRecorder()
+		srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tries/words/complete?prefix=ca", nil))
+		body := struct {
+			Words []string `json:"words"`
+		}{}
+		require.Empty(t, json.Unmarshal(rec.Body.Bytes(), &body), test.Name)
+		assert.Equal(t, test.ExpWords, body.Words, test.Name)
+	}
+}
+
+func TestServerRemoveWordWithSlash(t *testing.T) {
+	srv := newTestServer(t)
+	_, err := srv.tries["words"].Add("ca/t", nil)
+	require.Empty(t, err)
+
+	req := httptest.NewRequest(http.MethodDelete, "/admin/tries/words/words?word=ca%2Ft", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	assert.Empty(t, srv.tries["words"].WordsWithPrefix("ca/"))
+}
@@ -0,0 +1,200 @@
+package trie
+
+import (
+	"fmt"
+)
+
+// ResolveFunc decides the data of a word found in both tries of a set operation
+type ResolveFunc func(key string, va, vb interface{}) interface{}
+
+// setOp holds the state of a set operation while both tries are walked in parallel
+type setOp struct {
+	a, b     *Trie
+	res      *Trie
+	resolve  ResolveFunc
+	path     []rune
+	children childStack
+	err      error
+}
+
+// Union returns a new trie with the words of both tries. The data of a word found in both is given by
+// resolve, or taken from a if resolve is nil. Subtrees found in only one trie are copied without
+// being compared
+func Union(a, b *Trie, resolve ResolveFunc) (*Trie, error) {
+	op := newSetOp(a, b, resolve)
+	op.union(op.res.root(), a.root(), b.root())
+	return op.result()
+}
+
+// Intersect returns a new trie with the words found in both tries. The data of every word is given by
+// resolve, or taken from a if resolve is nil. Subtrees found in only one trie are skipped
+func Intersect(a, b *Trie, resolve ResolveFunc) (*Trie, error) {
+	op := newSetOp(a, b, resolve)
+	op.intersect(op.res.root(), a.root(), b.root())
+	return op.result()
+}
+
+// Difference returns a new trie with the words of a which are not in b, keeping their data from a.
+// Subtrees found only in a are copied without being compared and subtrees found only in b are skipped
+func Difference(a, b *Trie) (*Trie, error) {
+	op := newSetOp(a, b, nil)
+	op.difference(op.res.root(), a.root(), b.root())
+	return op.result()
+}
+
+// newSetOp creates a set operation whose result has the name, key mode, suffix index and node storage
+// of a, its suffix index included. Keys are compared as they are stored, so both tries should use the
+// same key mode
+func newSetOp(a, b *Trie, resolve ResolveFunc) *setOp {
+	opts := []Option{}
+	if a.suffix != nil {
+		opts = append(opts, WithSuffixIndex())
+	}
+	if _, ok := a.root().(arenaNode); ok {
+		opts = append(opts, WithArena())
+	}
+
+	res := New(a.Name, opts...)
+	if a.keyMode != nil {
+		res.keyMode = a.keyMode
+		res.spellings = make(map[string]string)
+	}
+
+	return &setOp{
+		a:       a,
+		b:       b,
+		res:     res,
+		resolve: resolve,
+	}
+}
+
+// result gives the trie built by the set operation, or the first error met while building it
+func (op *setOp) result() (*Trie, error) {
+	if op.err != nil {
+		return nil, op.err
+	}
+	return op.res, nil
+}
+
+// union merges the nodes of a and b into dst. Either node may be nil, in which case the other one is
+// copied
+func (op *setOp) union(dst, na, nb Node) {
+	aTerm := op.aTerm(na)
+	bTerm := op.bTerm(nb)
+	if aTerm && bTerm {
+		op.terminate(dst, op.resolveData(na.Data(), nb.Data()))
+	} else if aTerm {
+		op.terminate(dst, na.Data())
+	} else if bTerm {
+		op.terminate(dst, nb.Data())
+	}
+
+	if na != nil {
+		start, end := op.children.push(na, false)
+		for i := start; i < end; i++ {
+			ca := op.children[i]
+			var cb Node
+			if nb != nil {
+				cb, _ = nb.Child(ca.Value())
+			}
+			op.descend(dst, ca.Value(), func(cd Node) { op.union(cd, ca, cb) })
+		}
+		op.children.pop(start)
+	}
+	if nb != nil {
+		start, end := op.children.push(nb, false)
+		for i := start; i < end; i++ {
+			cb := op.children[i]
+			if na != nil {
+				if _, ok := na.Child(cb.Value()); ok {
+					continue
+				}
+			}
+			op.descend(dst, cb.Value(), func(cd Node) { op.union(cd, nil, cb) })
+		}
+		op.children.pop(start)
+	}
+}
+
+// intersect adds the words found below both na and nb to dst
+func (op *setOp) intersect(dst, na, nb Node) {
+	if op.aTerm(na) && op.bTerm(nb) {
+		op.terminate(dst, op.resolveData(na.Data(), nb.Data()))
+	}
+
+	start, end := op.children.push(na, false)
+	for i := start; i < end; i++ {
+		ca := op.children[i]
+		cb, ok := nb.Child(ca.Value())
+		if !ok {
+			continue
+		}
+		op.descend(dst, ca.Value(), func(cd Node) { op.intersect(cd, ca, cb) })
+	}
+	op.children.pop(start)
+}
+
+// difference adds the words found below na but not below nb to dst. The node nb may be nil, in which
+// case na is copied
+func (op *setOp) difference(dst, na, nb Node) {
+	if op.aTerm(na) && !op.bTerm(nb) {
+		op.terminate(dst, na.Data())
+	}
+
+	start, end := op.children.push(na, false)
+	for i := start; i < end; i++ {
+		ca := op.children[i]
+		var cb Node
+		if nb != nil {
+			cb, _ = nb.Child(ca.Value())
+		}
+		op.descend(dst, ca.Value(), func(cd Node) { op.difference(cd, ca, cb) })
+	}
+	op.children.pop(start)
+}
+
+// aTerm tells if the node of a, which may be nil, ends a word of a at the current path. Words whose
+// time to live has passed are left out
+func (op *setOp) aTerm(na Node) bool {
+	return na != nil && na.IsTerminal() && !op.a.expiredPath(op.path)
+}
+
+// bTerm tells if the node of b, which may be nil, ends a word of b at the current path
+func (op *setOp) bTerm(nb Node) bool {
+	return nb != nil && nb.IsTerminal() && !op.b.expiredPath(op.path)
+}
+
+// descend adds the child for the rune to dst, fills it with fn and drops it again if it ended up
+// without words
+func (op *setOp) descend(dst Node, r rune, fn func(cd Node)) {
+	op.path = append(op.path, r)
+	cd, _ := dst.(trieNode).addChild(r)
+	fn(cd)
+	if !cd.IsTerminal() && !cd.HasChildren() {
+		dst.(trieNode).removeChild(r)
+	}
+	op.path = op.path[:len(op.path)-1]
+}
+
+// terminate marks the node as the end of the word at the current path
+func (op *setOp) terminate(dst Node, data interface{}) {
+	dst.(trieNode).setTerm(true)
+	dst.(trieNode).setData(data)
+
+	key := string(op.path)
+	word := op.a.spelling(key)
+	if _, ok := op.a.spellings[key]; !ok {
+		word = op.b.spelling(key)
+	}
+	if err := op.res.indexKey(key, word); err != nil && op.err == nil {
+		op.err = fmt.Errorf("could not add word %s to suffix index: %s", word, err)
+	}
+}
+
+// resolveData gives the data of a word found in both tries
+func (op *setOp) resolveData(va, vb interface{}) interface{} {
+	if op.resolve == nil {
+		return va
+	}
+	return op.resolve(string(op.path), va, vb)
+}
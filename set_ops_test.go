@@ -0,0 +1,131 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetOps(t *testing.T) {
+	concat := func(key string, va, vb interface{}) interface{} {
+		return key + ":" + va.(string) + "+" + vb.(string)
+	}
+
+	var cases = []struct {
+		Name    string
+		Op      string //union,intersect,difference
+		Resolve ResolveFunc
+		ExpData map[string]interface{}
+	}{
+		{
+			Name:    "union keeps words of both tries",
+			Op:      "union",
+			Resolve: concat,
+			ExpData: map[string]interface{}{
+				"car": "car:a+b", "cart": "a", "care": "b", "dog": "a", "do": "b", "cat": "b",
+			},
+		},
+		{
+			Name: "union without resolve keeps data of a",
+			Op:   "union",
+			ExpData: map[string]interface{}{
+				"car": "a", "cart": "a", "care": "b", "dog": "a", "do": "b", "cat": "b",
+			},
+		},
+		{
+			Name:    "intersect keeps words found in both tries",
+			Op:      "intersect",
+			Resolve: concat,
+			ExpData: map[string]interface{}{
+				"car": "car:a+b",
+			},
+		},
+		{
+			Name: "difference keeps words of a not in b",
+			Op:   "difference",
+			ExpData: map[string]interface{}{
+				"cart": "a", "dog": "a",
+			},
+		},
+	}
+
+	for _, test := range cases {
+		a := New("a")
+		for _, word := range []string{"car", "cart", "dog"} {
+			_, err := a.Add(word, "a")
+			require.Empty(t, err, test.Name)
+		}
+		b := New("b")
+		for _, word := range []string{"car", "care", "do", "cat"} {
+			_, err := b.Add(word, "b")
+			require.Empty(t, err, test.Name)
+		}
+
+		var res *Trie
+		var err error
+		if test.Op == "union" {
+			res, err = Union(a, b, test.Resolve)
+		} else if test.Op == "intersect" {
+			res, err = Intersect(a, b, test.Resolve)
+		} else if test.Op == "difference" {
+			res, err = Difference(a, b)
+		}
+		require.Empty(t, err, test.Name)
+
+		data := map[string]interface{}{}
+		for _, word := range res.Words() {
+			n, err := res.Find(word)
+			require.Empty(t, err, test.Name)
+			data[word] = n.Data()
+		}
+		assert.Equal(t, test.ExpData, data, test.Name)
+
+		// Branches without words must not be left behind
+		assert.Equal(t, len(test.ExpData), len(res.Words()), test.Name)
+		for r, n := range res.Root.Children() {
+			assert.NotEmpty(t, res.WordsWithPrefix(string(r)), test.Name+": empty branch "+string(n.Value()))
+		}
+
+		// The inputs are left untouched
+		assert.ElementsMatch(t, []string{"car", "cart", "dog"}, a.Words(), test.Name)
+		assert.ElementsMatch(t, []string{"car", "care", "do", "cat"}, b.Words(), test.Name)
+	}
+}
+
+func TestSetOpsKeepIndexes(t *testing.T) {
+	a := New("a", WithCaseFolding(), WithSuffixIndex())
+	_, err := a.Add("Walking", nil)
+	require.Empty(t, err)
+	b := New("b", WithCaseFolding(), WithSuffixIndex())
+	_, err = b.Add("Talking", nil)
+	require.Empty(t, err)
+
+	res, err := Union(a, b, nil)
+	require.Empty(t, err)
+
+	assert.ElementsMatch(t, []string{"Walking", "Talking"}, res.Words())
+	words, err := res.WordsWithSuffix("ING")
+	require.Empty(t, err)
+	assert.ElementsMatch(t, []string{"Walking", "Talking"}, words)
+}
+
+func TestSetOpsKeepStorage(t *testing.T) {
+	a := New("a", WithArena(), WithSuffixIndex())
+	_, err := a.Add("walking", nil)
+	require.Empty(t, err)
+	b := New("b")
+	_, err = b.Add("talking", nil)
+	require.Empty(t, err)
+
+	res, err := Union(a, b, nil)
+	require.Empty(t, err)
+
+	_, ok := res.root().(arenaNode)
+	assert.True(t, ok)
+	_, ok = res.suffix.root().(arenaNode)
+	assert.True(t, ok)
+	words, err := res.WordsWithSuffix("ing")
+	require.Empty(t, err)
+	assert.ElementsMatch(t, []string{"walking", "talking"}, words)
+}
@@ -0,0 +1,92 @@
+package trie
+
+import (
+	"unsafe"
+)
+
+// Sizes used to estimate the heap taken by the map of children of a node. A map header is
+// allocated for every node and buckets of 8 entries are added as the map grows past its load factor
+const (
+	mapHeaderBytes = 48
+	mapBucketSize  = 8
+	mapLoadFactor  = 6.5
+)
+
+// Stats describes the shape and estimated memory use of a trie
+type Stats struct {
+	// Words is the number of words in the trie
+	Words int
+	// Nodes is the number of nodes in the trie including the root
+	Nodes int
+	// MaxDepth is the depth of the deepest node, which is the length of the longest word
+	MaxDepth int
+	// AvgDepth is the average depth of the terminating nodes, which is the average word length
+	AvgDepth float64
+	// Branching maps a number of children to the number of nodes having that many children
+	Branching map[int]int
+	// ChainNodes is the number of non-terminating nodes with a single child, which path compression
+	// would remove
+	ChainNodes int
+	// HeapBytes is an estimate of the heap used by the nodes and their maps of children. Data held by
+	// the nodes is not included
+	HeapBytes int64
+}
+
+// Stats walks the trie and returns its statistics
+func (t *Trie) Stats() Stats {
+	stats := Stats{
+		Branching: map[int]int{},
+	}
+	depthSum := 0
+	var buf []Node
+
+	t.walk(func(path []rune, n Node) WalkAction {
+		buf = n.(trieNode).appendChildren(buf[:0])
+		children := len(buf)
+
+		stats.Nodes++
+		stats.Branching[children]++
+		if _, ok := n.(arenaNode); ok {
+			stats.HeapBytes += arenaSlotBytes
+		} else {
+			stats.HeapBytes += nodeHeapBytes(children)
+		}
+		if len(path) > stats.MaxDepth {
+			stats.MaxDepth = len(path)
+		}
+		// A word whose time to live has passed is no longer counted
+		if n.IsTerminal() && !t.expiredPath(path) {
+			stats.Words++
+			depthSum += len(path)
+		} else if children == 1 && !n.IsRoot() {
+			stats.ChainNodes++
+		}
+
+		return Continue
+	})
+
+	if stats.Words > 0 {
+		stats.AvgDepth = float64(depthSum) / float64(stats.Words)
+	}
+
+	return stats
+}
+
+// nodeHeapBytes estimates the heap used by a node with the number of children specified
+func nodeHeapBytes(children int) int64 {
+	bytes := int64(unsafe.Sizeof(node{})) + mapHeaderBytes
+	if children == 0 {
+		return bytes
+	}
+
+	buckets := 1
+	for float64(buckets)*mapLoadFactor < float64(children) {
+		buckets *= 2
+	}
+
+	var r rune
+	var n Node
+	bucketBytes := mapBucketSize + mapBucketSize*int64(unsafe.Sizeof(r)) + mapBucketSize*int64(unsafe.Sizeof(n)) + int64(unsafe.Sizeof(uintptr(0)))
+
+	return bytes + int64(buckets)*bucketBytes
+}
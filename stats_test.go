@@ -0,0 +1,55 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	var cases = []struct {
+		Name         string
+		Words        []string
+		ExpWords     int
+		ExpNodes     int
+		ExpMaxDepth  int
+		ExpAvgDepth  float64
+		ExpBranching map[int]int
+		ExpChain     int
+	}{
+		{
+			Name:         "empty trie has only the root",
+			ExpNodes:     1,
+			ExpBranching: map[int]int{0: 1},
+		},
+		{
+			Name:         "shape of the trie is described",
+			Words:        []string{"car", "cart", "cat", "dog"},
+			ExpWords:     4,
+			ExpNodes:     9,
+			ExpMaxDepth:  4,
+			ExpAvgDepth:  3.25,
+			ExpBranching: map[int]int{0: 3, 1: 4, 2: 2},
+			ExpChain:     3,
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test")
+		for _, word := range test.Words {
+			_, err := tr.Add(word, nil)
+			require.Empty(t, err, test.Name)
+		}
+
+		stats := tr.Stats()
+
+		assert.Equal(t, test.ExpWords, stats.Words, test.Name)
+		assert.Equal(t, test.ExpNodes, stats.Nodes, test.Name)
+		assert.Equal(t, test.ExpMaxDepth, stats.MaxDepth, test.Name)
+		assert.Equal(t, test.ExpAvgDepth, stats.AvgDepth, test.Name)
+		assert.Equal(t, test.ExpBranching, stats.Branching, test.Name)
+		assert.Equal(t, test.ExpChain, stats.ChainNodes, test.Name)
+		assert.Greater(t, stats.HeapBytes, int64(0), test.Name)
+	}
+}
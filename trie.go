@@ -2,10 +2,11 @@
 package trie
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"sort"
+	"time"
 
 	"github.com/disiqueira/gotree/v3"
 )
@@ -25,24 +26,81 @@ func (w *wordArray) add(word string) {
 type Trie struct {
 	Root Node
 	Name string
+
+	// suffix mirrors the trie with every word reversed when the suffix index is enabled
+	suffix *Trie
+
+	// keyMode transforms words into keys when normalization or folding is enabled, in which case
+	// spellings maps every key to the word as it was added
+	keyMode   *keyMode
+	spellings map[string]string
+
+	// undo records the changes made during a batch so they can be reverted
+	undo *undoLog
+
+	// events holds the observers of the trie
+	events *eventHub
+
+	// ttl holds the deadlines of the words added with a time to live
+	ttl *expiry
+
+	// usage holds the capacity of the trie and the use of its words when it is bounded
+	usage *capacity
+}
+
+// Option configures a trie created with New
+type Option func(*Trie)
+
+// WithSuffixIndex keeps a mirrored trie of reversed words in sync with Add and Remove so that
+// suffix queries do not need a full scan
+func WithSuffixIndex() Option {
+	return func(t *Trie) {
+		t.suffix = New(t.Name + " (suffix)")
+	}
 }
 
 // New creates a trie with name specified. If no name is specified then "Trie" is used
-func New(name string) *Trie {
+func New(name string, opts ...Option) *Trie {
 	if name == "" {
 		name = trieName
 	}
-	return &Trie{
+	t := &Trie{
 		Root: &node{
 			children: make(childNodeMap),
 			isRoot:   true,
 		},
-		Name: name,
+		Name:   name,
+		events: &eventHub{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	// The suffix index stores its nodes like the trie whatever the order of the options
+	if _, ok := t.root().(arenaNode); ok && t.suffix != nil {
+		if _, ok := t.suffix.root().(arenaNode); !ok {
+			WithArena()(t.suffix)
+		}
 	}
+
+	return t
 }
 
 // NewFromFile creates a trie from a file
 func NewFromFile(file string, name string) (*Trie, error) {
+	return newFromFile(file, LoadOptions{Name: name})
+}
+
+// NewFromFileParallel creates a trie from a file like NewFromFile using several goroutines. Lines
+// are partitioned by the first rune of their word, every worker builds the sub-trie of its
+// partition and the sub-tries are grafted under the root at the end. The trie is the same as the
+// one NewFromFile creates
+func NewFromFileParallel(file string, name string, workers int) (*Trie, error) {
+	return newFromFile(file, LoadOptions{Name: name, Workers: workers})
+}
+
+// newFromFile creates a trie from a file with the load options specified
+func newFromFile(file string, opts LoadOptions) (*Trie, error) {
 	if len(file) == 0 {
 		return nil, fmt.Errorf("file is required")
 	}
@@ -52,30 +110,66 @@ func NewFromFile(file string, name string) (*Trie, error) {
 	}
 	defer fh.Close()
 
-	fs := bufio.NewScanner(fh)
-	tr := New(name)
-
-	for fs.Scan() {
-		word := fs.Text()
-		if len(word) > 0 {
-			tr.Add(word, "")
-		}
+	// Lines which cannot be added, such as repeated words, are skipped
+	tr, err := NewFromReader(fh, opts)
+	var loadErr *LoadError
+	if err != nil && !errors.As(err, &loadErr) {
+		return nil, fmt.Errorf("could not read file %s: %s", file, err)
 	}
 
 	return tr, nil
 }
 
+// root gives the root of the trie as the package works with it, which for an arena is the node
+// behind the handle in Root
+func (t *Trie) root() Node {
+	if h, ok := t.Root.(arenaHandle); ok {
+		return h.arenaNode
+	}
+	return t.Root
+}
+
 // Find check if the trie has the word and return the terminating node of the word
 func (t *Trie) Find(word string) (Node, error) {
+	termNode, err := t.find(word)
+	if err != nil {
+		return nil, err
+	}
+	t.touch(t.key(word))
+
+	return expose(termNode), nil
+}
+
+// Peek checks if the trie has the word and returns the terminating node of the word like Find,
+// without counting it as a use of the word on a trie with a capacity
+func (t *Trie) Peek(word string) (Node, error) {
+	termNode, err := t.find(word)
+	if err != nil {
+		return nil, err
+	}
+
+	return expose(termNode), nil
+}
+
+// find gets the terminating node of the word without counting it as a use of the word
+func (t *Trie) find(word string) (Node, error) {
 	if len(word) == 0 {
-		return nil, fmt.Errorf("no string to find")
+		return nil, errorf(ErrEmptyKey, "no string to find")
 	}
 
-	runes := []rune(word)
+	runes := []rune(t.key(word))
+	if len(runes) == 0 {
+		return nil, errorf(ErrEmptyKey, "no string to find")
+	}
 
-	termNode, err := t.findAtNode(t.Root, runes, 0)
+	termNode, err := t.findAtNode(t.root(), runes, 0)
 	if err != nil {
-		return nil, fmt.Errorf("word %s not found: %s", word, err)
+		return nil, fmt.Errorf("word %s not found: %w", word, err)
+	}
+
+	// A word whose time to live has passed is gone for readers even before it is removed
+	if t.expiredPath(runes) {
+		return nil, &NotFoundError{Key: string(runes), Prefix: string(runes), Pos: len(runes)}
 	}
 
 	return termNode, nil
@@ -83,22 +177,22 @@ func (t *Trie) Find(word string) (Node, error) {
 
 // findAtNode gets the node beginning from specified node where the runes terminate
 func (t *Trie) findAtNode(n Node, runes []rune, pos int) (Node, error) {
+	if an, ok := n.(arenaNode); ok {
+		return an.a.find(an.i, runes, pos)
+	}
+
 	r := runes[pos]
-	cNode, ok := n.Children()[r]
+	cNode, ok := n.Child(r)
 	if !ok {
-		var prefix string
-		if pos > 0 {
-			prefix = string(runes[0 : pos-1])
-		}
-		return nil, fmt.Errorf("string %s not found, longest prefix found: %s", string(runes), prefix)
+		return nil, &NotFoundError{Key: string(runes), Prefix: string(runes[:pos]), Pos: pos}
 	}
 
 	if pos < (len(runes) - 1) {
 		pos = pos + 1
 	} else {
 		// This was the last character, check if the node is terminating
-		if !cNode.IsTerm() {
-			return nil, fmt.Errorf("string %s not found but exists as a non-terminated path", string(runes))
+		if !cNode.IsTerminal() {
+			return nil, &NotFoundError{Key: string(runes), Prefix: string(runes), Pos: len(runes)}
 		}
 		return cNode, nil
 	}
@@ -108,58 +202,133 @@ func (t *Trie) findAtNode(n Node, runes []rune, pos int) (Node, error) {
 
 // Remove removes the word from the trie. An error is returned is the word is not in the trie
 func (t *Trie) Remove(word string) error {
-	termNode, err := t.Find(word)
+	t.reap(t.key(word))
+	termNode, err := t.find(word)
 	if err != nil {
-		return fmt.Errorf("could not find word %s in trie: %s", word, err)
+		return fmt.Errorf("could not find word %s in trie: %w", word, err)
 	}
 
-	termNode.SetTerm(false)
-
-	curNode := termNode
-	for !curNode.IsTerm() && !curNode.IsRoot() && len(curNode.Children()) == 0 {
-		curNode.Parent().RemoveChild(curNode.Value())
-		curNode = curNode.Parent()
+	// The node may be freed once it is pruned, so its data is taken first. The suffix index is
+	// updated first so the trie is left as it was if that fails
+	key := t.key(word)
+	spelling, data := t.spelling(key), termNode.Data()
+	if err := t.unindexKey(key); err != nil {
+		return fmt.Errorf("could not remove word %s from suffix index: %s", word, err)
 	}
+	t.unterminate(termNode)
+
+	t.emit(Change{Type: Removed, Key: spelling, Old: data})
 
 	return nil
 }
 
+// unterminate clears the terminal flag of the node and prunes the branch up to the closest node
+// which is still needed by another word
+func (t *Trie) unterminate(termNode Node) {
+	t.setTerm(termNode, false)
+
+	curNode := termNode
+	for !curNode.IsTerminal() && !curNode.IsRoot() && !curNode.HasChildren() {
+		// The parent is taken first as the node may be freed when it is removed
+		parent := curNode.Parent()
+		t.removeChild(parent, curNode.Value())
+		curNode = parent
+	}
+}
+
 // Add adds a word to the trie and returns the terminating node. If the word already
 // exists in the trie an error is returned
 func (t *Trie) Add(word string, data interface{}) (Node, error) {
+	termNode, err := t.add(word, data)
+	if err != nil {
+		return nil, err
+	}
+	return expose(termNode), nil
+}
+
+// add adds a word to the trie like Add without handing out its node
+func (t *Trie) add(word string, data interface{}) (Node, error) {
 	if len(word) == 0 {
-		return nil, fmt.Errorf("no string to add")
+		return nil, errorf(ErrEmptyKey, "no string to add")
+	}
+
+	key := t.key(word)
+	if len(key) == 0 {
+		return nil, errorf(ErrEmptyKey, "no string to add")
+	}
+
+	t.reap(key)
+	termNode, err := t.addAtNode(t.root(), []rune(key), data)
+	if err != nil {
+		return nil, &ExistsError{Word: word}
+	}
+
+	if err := t.indexKey(key, word); err != nil {
+		t.setData(termNode, nil)
+		t.unterminate(termNode)
+		return nil, fmt.Errorf("could not add word %s to suffix index: %s", word, err)
+	}
+	t.emit(Change{Type: Added, Key: word, New: data})
+	t.evict(key)
+
+	return termNode, nil
+}
+
+// indexKey records a key which now terminates a word in the suffix index, the spellings and the use
+// of the words. Nothing is recorded if the suffix index cannot take the key
+func (t *Trie) indexKey(key string, word string) error {
+	if t.suffix != nil {
+		if _, err := t.suffix.add(reverse(key), nil); err != nil {
+			return err
+		}
+	}
+
+	t.setSpelling(key, word)
+	t.track(key)
+
+	return nil
+}
+
+// unindexKey forgets a key which no longer terminates a word in the suffix index, the spellings, the
+// deadlines and the use of the words. Nothing is forgotten if the suffix index does not have the key
+func (t *Trie) unindexKey(key string) error {
+	if t.suffix != nil {
+		if err := t.suffix.Remove(reverse(key)); err != nil {
+			return err
+		}
 	}
 
-	runes := []rune(word)
-	return t.addAtNode(t.Root, runes, data)
+	t.setSpelling(key, "")
+	t.setDeadline(key, time.Time{})
+	t.untrack(key)
+
+	return nil
 }
 
 // addAtNode adds runes starting at node specified and returns the terminating node
 func (t *Trie) addAtNode(n Node, runes []rune, data interface{}) (Node, error) {
-	r := runes[0]
-	nResult := n.AddChild(r)
+	cNode := t.addPath(n, runes)
 
-	var cRunes []rune
-	if len(runes) > 1 {
-		cRunes = runes[1:]
-	} else {
-		// This was the last character so we should check if this is a terminator
-		if nResult.result == nodeFound {
-			if nResult.Node.IsTerm() {
-				return nil, fmt.Errorf("word already exists in trie")
-			}
-			nResult.Node.SetTerm(false)
-		}
-		nResult.Node.SetData(data)
-		return nResult.Node, nil
+	// This was the last character so we should check if this is a terminator
+	if cNode.IsTerminal() {
+		return nil, ErrExists
 	}
+	t.setTerm(cNode, true)
+	t.setData(cNode, data)
 
-	cn, err := t.addAtNode(nResult.Node, cRunes, data)
-	if err != nil {
-		return nil, err
+	return cNode, nil
+}
+
+// addPath gets or adds the nodes along the runes from the node specified and returns the last one
+func (t *Trie) addPath(n Node, runes []rune) Node {
+	if an, ok := n.(arenaNode); ok {
+		return t.addArenaPath(an, runes)
 	}
-	return cn, nil
+
+	for _, r := range runes {
+		n = t.addChild(n, r)
+	}
+	return n
 }
 
 // Words returns an array of words in the trie
@@ -168,9 +337,9 @@ func (t *Trie) Words() []string {
 		words: []string{},
 	}
 
-	t.wordsAtNode(t.Root, "", words)
+	t.wordsAtNode(t.root(), []rune{}, words, &childStack{})
 
-	return words.words
+	return t.spellingsOf(words.words)
 }
 
 // Equal checks if the trie is the same as compareTo
@@ -178,22 +347,148 @@ func (t *Trie) Equal(compareTo *Trie) bool {
 	return t.String() == compareTo.String()
 }
 
-// wordsAtNode returns all words that occur after the node specified
-func (t *Trie) wordsAtNode(n Node, tillThis string, words *wordArray) {
-	if n.IsTerm() {
-		words.add(tillThis)
+// wordsAtNode adds the keys of all words at or below the node specified, whose key is tillThis
+func (t *Trie) wordsAtNode(n Node, tillThis []rune, words *wordArray, children *childStack) {
+	if n.IsTerminal() && !t.expiredPath(tillThis) {
+		words.add(string(tillThis))
+	}
+
+	start, end := children.push(n, false)
+	for i := start; i < end; i++ {
+		cNode := (*children)[i]
+		t.wordsAtNode(cNode, append(tillThis, cNode.Value()), words, children)
+	}
+	children.pop(start)
+}
+
+// nodeAtPrefix returns the node where the runes end, whether terminating or not, or nil if the
+// trie has no such path
+func (t *Trie) nodeAtPrefix(runes []rune) Node {
+	if an, ok := t.root().(arenaNode); ok {
+		if i, matched := an.a.descend(an.i, runes); matched == len(runes) {
+			return arenaNode{a: an.a, i: i}
+		}
+		return nil
+	}
+
+	n := t.root()
+	for _, r := range runes {
+		cNode, ok := n.Child(r)
+		if !ok {
+			return nil
+		}
+		n = cNode
+	}
+	return n
+}
+
+// WordsWithPrefix returns an array of words in the trie that begin with prefix
+func (t *Trie) WordsWithPrefix(prefix string) []string {
+	words := &wordArray{
+		words: []string{},
 	}
 
-	for r, cNode := range n.Children() {
-		t.wordsAtNode(cNode, tillThis+string(r), words)
+	runes := []rune(t.key(prefix))
+	if n := t.nodeAtPrefix(runes); n != nil {
+		t.wordsAtNode(n, runes, words, &childStack{})
 	}
+	// Listing every word is not a use of each of them
+	if len(runes) > 0 {
+		for _, key := range words.words {
+			t.touch(key)
+		}
+	}
+
+	return t.spellingsOf(words.words)
+}
+
+// LongestPrefixOf returns the longest word in the trie that is a prefix of s. An error is returned
+// if no word in the trie is a prefix of s
+func (t *Trie) LongestPrefixOf(s string) (string, error) {
+	runes := []rune(t.key(s))
+
+	_, length := t.longestPrefixAtNode(t.root(), runes)
+	if length == 0 {
+		return "", errorf(ErrNotFound, "no prefix of %s found in trie", s)
+	}
+	t.touch(string(runes[:length]))
+
+	return t.spelling(string(runes[:length])), nil
+}
+
+// longestPrefixAtNode walks the runes beginning from the node specified and returns the deepest
+// terminating node on the way along with the number of runes needed to reach it
+func (t *Trie) longestPrefixAtNode(n Node, runes []rune) (Node, int) {
+	var termNode Node
+	length := 0
+
+	for i, r := range runes {
+		cNode, ok := n.Child(r)
+		if !ok {
+			break
+		}
+		if cNode.IsTerminal() && !t.expiredPath(runes[:i+1]) {
+			termNode = cNode
+			length = i + 1
+		}
+		n = cNode
+	}
+
+	return termNode, length
+}
+
+// WordsWithSuffix returns an array of words in the trie that end with suffix. The trie must have
+// been created with WithSuffixIndex
+func (t *Trie) WordsWithSuffix(suffix string) ([]string, error) {
+	if t.suffix == nil {
+		return nil, fmt.Errorf("suffix index is not enabled")
+	}
+
+	reversed := t.suffix.WordsWithPrefix(reverse(t.key(suffix)))
+	words := reversed[:0]
+	for _, word := range reversed {
+		if key := reverse(word); !t.expired(key) {
+			words = append(words, key)
+		}
+	}
+
+	return t.spellingsOf(words), nil
+}
+
+// LongestSuffixOf returns the longest word in the trie that is a suffix of s. The trie must have
+// been created with WithSuffixIndex
+func (t *Trie) LongestSuffixOf(s string) (string, error) {
+	if t.suffix == nil {
+		return "", fmt.Errorf("suffix index is not enabled")
+	}
+
+	// The suffix index is walked here rather than through its LongestPrefixOf so that words whose
+	// time to live has passed are skipped
+	runes := []rune(reverse(t.key(s)))
+	n := t.suffix.root()
+	length := 0
+	for i, r := range runes {
+		cNode, ok := n.Child(r)
+		if !ok {
+			break
+		}
+		if cNode.IsTerminal() && (t.ttl == nil || !t.expired(reverse(string(runes[:i+1])))) {
+			length = i + 1
+		}
+		n = cNode
+	}
+	if length == 0 {
+		return "", errorf(ErrNotFound, "no suffix of %s found in trie", s)
+	}
+
+	return t.spelling(reverse(string(runes[:length]))), nil
 }
 
 // Tree gives a goTree for the trie
 func (t *Trie) Tree() gotree.Tree {
 	tree := gotree.New(t.Name)
 
-	t.treeAtNode(t.Root, tree)
+	t.treeAtNode(t.root(), tree)
 
 	return tree
 }
@@ -201,18 +496,11 @@ func (t *Trie) Tree() gotree.Tree {
 // treeAtNode gives the tree beginning from the node specified
 func (t *Trie) treeAtNode(n Node, tree gotree.Tree) {
 	// Sort child runes so that the trie viz is consistent
-	runes := make(runeSlice, len(n.Children()))
-	i := 0
-	for r := range n.Children() {
-		runes[i] = r
-		i++
-	}
-	sort.Sort(runeSlice(runes))
-
-	for _, r := range runes {
+	for _, r := range sortedRunes(n) {
 		label := string(r)
 		leaf := tree.Add(label)
-		t.treeAtNode(n.Children()[r], leaf)
+		cNode, _ := n.Child(r)
+		t.treeAtNode(cNode, leaf)
 	}
 }
 
@@ -221,6 +509,26 @@ func (t *Trie) String() string {
 	return t.Tree().Print()
 }
 
+// sortedRunes returns the runes of the children of the node in ascending order
+func sortedRunes(n Node) []rune {
+	children := n.(trieNode).appendChildren(nil)
+	runes := make(runeSlice, len(children))
+	for i, cNode := range children {
+		runes[i] = cNode.Value()
+	}
+	sort.Sort(runes)
+	return runes
+}
+
+// reverse returns the string with its runes in reverse order
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
 // Sadly you have to implement a sort interface for a rune :-(
 type runeSlice []rune
 
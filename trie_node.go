@@ -1,27 +1,47 @@
 package trie
 
-// childNodeMap defines a map from a rune to trie node and represents children of a trie node
-type childNodeMap map[rune]Node
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
 
-const nodeFound = "FOUND"
-const nodeAdded = "ADDED"
+// childNodeMap defines a map from a rune to trie node and represents children of a trie node
+type childNodeMap = map[rune]Node
 
-// Node defines an interface for a node
+// Node defines an interface for a node. The path of a node is made of the runes as they are
+// stored, which are the normalized keys when the trie has a key mode
 type Node interface {
 	Value() rune
 	Parent() Node
-	Children() childNodeMap
-	IsTerm() bool // a word ends here
+	Children() map[rune]Node
+	Child(r rune) (Node, bool)
+	SortedChildren() []Node
+	Data() interface{}
+	Path() string
+	Depth() int
+	HasChildren() bool
+	IsTerminal() bool // a word ends here
 	IsRoot() bool
 
-	AddChild(r rune) *nodeResult
-	RemoveChild(r rune)
-	SetTerm(term bool)
-	SetData(data interface{})
-
 	Equal(tn2 Node) bool
 }
 
+// trieNode is implemented by the nodes of the package. A trie only changes its nodes through it, so
+// the nodes it gives out are read-only
+type trieNode interface {
+	Node
+
+	appendChildren(dst []Node) []Node
+	addChild(r rune) (Node, bool)
+	removeChild(r rune)
+	detachChild(r rune) (Node, bool)
+	attachChild(c Node)
+	release(c Node)
+	setTerm(term bool)
+	setData(data interface{})
+}
+
 // node represents a node in the trie
 type node struct {
 	value    rune
@@ -34,13 +54,6 @@ type node struct {
 	childCount int
 }
 
-// nodeResult represents the result of adding a node. It includes the node found/added as well as
-// a the result string which tells you whether the node was found or added
-type nodeResult struct {
-	result string
-	Node
-}
-
 // Value gives the rune in the trie node
 func (tn *node) Value() rune {
 	return tn.value
@@ -51,9 +64,63 @@ func (tn *node) Parent() Node {
 	return tn.parent
 }
 
-// Children gives the child nodes of the trie node
-func (tn *node) Children() childNodeMap {
-	return tn.children
+// Children gives a map of the child nodes of the trie node. Changing the map does not change the
+// children
+func (tn *node) Children() map[rune]Node {
+	children := make(childNodeMap, len(tn.children))
+	for r, cNode := range tn.children {
+		children[r] = cNode
+	}
+	return children
+}
+
+// Child gives the child node for the rune and whether there is one
+func (tn *node) Child(r rune) (Node, bool) {
+	cNode, ok := tn.children[r]
+	return cNode, ok
+}
+
+// SortedChildren gives the child nodes of the trie node in ascending rune order
+func (tn *node) SortedChildren() []Node {
+	runes := make(runeSlice, 0, len(tn.children))
+	for r := range tn.children {
+		runes = append(runes, r)
+	}
+	sort.Sort(runes)
+
+	children := make([]Node, len(runes))
+	for i, r := range runes {
+		children[i] = tn.children[r]
+	}
+	return children
+}
+
+// appendChildren appends the child nodes of the trie node to dst and returns the extended slice
+func (tn *node) appendChildren(dst []Node) []Node {
+	for _, cNode := range tn.children {
+		dst = append(dst, cNode)
+	}
+	return dst
+}
+
+// Path gives the runes from the root to the trie node as a string. The path of the root is empty
+func (tn *node) Path() string {
+	runes := make([]rune, tn.Depth())
+	var n Node = tn
+	for i := len(runes) - 1; i >= 0; i-- {
+		runes[i] = n.Value()
+		n = n.Parent()
+	}
+	return string(runes)
+}
+
+// Depth gives the number of runes from the root to the trie node. The depth of the root is 0
+func (tn *node) Depth() int {
+	depth := 0
+	for n := Node(tn); !n.IsRoot() && n.Parent() != nil; n = n.Parent() {
+		depth++
+	}
+	return depth
 }
 
 // Data gives the data in the node
@@ -61,8 +128,8 @@ func (tn *node) Data() interface{} {
 	return tn.data
 }
 
-// SetData sets the data in the node
-func (tn *node) SetData(v interface{}) {
+// setData sets the data in the node
+func (tn *node) setData(v interface{}) {
 	tn.data = v
 }
 
@@ -71,8 +138,8 @@ func (tn *node) HasChildren() bool {
 	return len(tn.children) > 0
 }
 
-// IsTerm returns true if the trie node is a terminating node
-func (tn *node) IsTerm() bool {
+// IsTerminal returns true if the trie node is a terminating node
+func (tn *node) IsTerminal() bool {
 	return tn.isTerm
 }
 
@@ -81,40 +148,32 @@ func (tn *node) IsRoot() bool {
 	return tn.isRoot
 }
 
-// AddChild attempts to add a node and returns a nodeResult encapsulating results of the action
-func (tn *node) AddChild(r rune) *nodeResult {
-	if tn.children == nil {
-		tn.children = make(childNodeMap)
+// addChild returns the child node for the rune along with true if it had to be added. An added child
+// is not terminating and has no map of children until it gets one
+func (tn *node) addChild(r rune) (Node, bool) {
+	if cNode, ok := tn.children[r]; ok {
+		return cNode, false
 	}
 
-	if foundN, ok := tn.children[r]; ok {
-		return &nodeResult{
-			result: nodeFound,
-			Node:   foundN,
-		}
+	if tn.children == nil {
+		tn.children = make(childNodeMap)
 	}
-
-	tn.children[r] = &node{
-		value:    r,
-		parent:   tn,
-		children: make(childNodeMap),
-		isTerm:   true,
-		isRoot:   false,
+	cNode := &node{
+		value:  r,
+		parent: tn,
 	}
-	tn.isTerm = false
+	tn.children[r] = cNode
 
-	return &nodeResult{
-		result: nodeAdded,
-		Node:   tn.children[r],
-	}
+	return cNode, true
 }
 
-func (tn *node) RemoveChild(r rune) {
+// removeChild removes the child node for the rune
+func (tn *node) removeChild(r rune) {
 	delete(tn.children, r)
 }
 
-// SetTerm marks the trie node as terminating
-func (tn *node) SetTerm(term bool) {
+// setTerm marks the trie node as terminating
+func (tn *node) setTerm(term bool) {
 	tn.isTerm = term
 }
 
@@ -132,7 +191,7 @@ func (tn *node) Equal(tn2 Node) bool {
 	}
 
 	// Compare node specific values
-	if tn.value != tn2.Value() || tn.isRoot != tn2.IsRoot() || tn.isTerm != tn2.IsTerm() {
+	if tn.value != tn2.Value() || tn.isRoot != tn2.IsRoot() || tn.isTerm != tn2.IsTerminal() {
 		return false
 	}
 
@@ -148,21 +207,70 @@ func (tn *node) Equal(tn2 Node) bool {
 	}
 
 	// Compare children
-	for cRune, cNode := range tn.children {
-		c2Node, ok := tn2.Children()[cRune]
-		if !ok {
-			return false
-		}
-		if cNode.Value() != c2Node.Value() {
-			return false
-		}
+	children2 := tn2.SortedChildren()
+	if len(tn.children) != len(children2) {
+		return false
 	}
-	for c2Rune := range tn2.Children() {
-		_, ok := tn.children[c2Rune]
-		if !ok {
+	for _, c2Node := range children2 {
+		cNode, ok := tn.children[c2Node.Value()]
+		if !ok || cNode.Value() != c2Node.Value() {
 			return false
 		}
 	}
 
 	return true
 }
+
+// detachChild removes the child node for the rune and gives it, so it can be attached again
+func (tn *node) detachChild(r rune) (Node, bool) {
+	cNode, ok := tn.children[r]
+	if ok {
+		delete(tn.children, r)
+	}
+	return cNode, ok
+}
+
+// attachChild adds a detached node as a child. Nodes of an arena are copied
+func (tn *node) attachChild(c Node) {
+	cNode, ok := c.(*node)
+	if !ok {
+		copyNode(tn, c)
+		return
+	}
+
+	if tn.children == nil {
+		tn.children = make(childNodeMap)
+	}
+	cNode.parent = tn
+	tn.children[cNode.value] = cNode
+}
+
+// release does nothing as detached nodes are left to the garbage collector
+func (tn *node) release(c Node) {}
+
+// childStack holds the children of the nodes being visited by a recursive walk. Every level takes
+// its children from the top of the stack and drops them once it is done, so visiting a node does
+// not allocate a map or a slice of its children
+type childStack []Node
+
+// push adds the children of the node, in ascending rune order if sorted is true, and gives where
+// they begin and end in the stack
+func (s *childStack) push(n Node, sorted bool) (int, int) {
+	start := len(*s)
+	*s = n.(trieNode).appendChildren(*s)
+
+	// The children of an arena are already in order
+	if _, ok := n.(*node); ok && sorted {
+		slices.SortFunc((*s)[start:], func(a, b Node) int {
+			return cmp.Compare(a.Value(), b.Value())
+		})
+	}
+
+	return start, len(*s)
+}
+
+// pop drops the children pushed from start
+func (s *childStack) pop(start int) {
+	clear((*s)[start:])
+	*s = (*s)[:start]
+}
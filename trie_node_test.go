@@ -59,13 +59,13 @@ func TestNodeFun(t *testing.T) {
 			Out:  data,
 		},
 		{
-			Name: "SetData works correctly",
-			Fun:  "SetData",
+			Name: "setData works correctly",
+			Fun:  "setData",
 			Out:  changeData,
 		},
 		{
-			Name: "IsTerm works correctly",
-			Fun:  "IsTerm",
+			Name: "IsTerminal works correctly",
+			Fun:  "IsTerminal",
 			Out:  isTerm,
 		},
 		{
@@ -74,8 +74,8 @@ func TestNodeFun(t *testing.T) {
 			Out:  isRoot,
 		},
 		{
-			Name: "SetTerm works correctly",
-			Fun:  "SetTerm",
+			Name: "setTerm works correctly",
+			Fun:  "setTerm",
 			Out:  true,
 		},
 	}
@@ -93,15 +93,15 @@ func TestNodeFun(t *testing.T) {
 			op = n.HasChildren()
 		} else if test.Fun == "Data" {
 			op = n.Data()
-		} else if test.Fun == "SetData" {
-			n.SetData(changeData)
+		} else if test.Fun == "setData" {
+			n.setData(changeData)
 			op = n.data
-		} else if test.Fun == "IsTerm" {
-			op = n.IsTerm()
+		} else if test.Fun == "IsTerminal" {
+			op = n.IsTerminal()
 		} else if test.Fun == "IsRoot" {
 			op = n.IsRoot()
-		} else if test.Fun == "SetTerm" {
-			n.SetTerm(true)
+		} else if test.Fun == "setTerm" {
+			n.setTerm(true)
 			op = n.isTerm
 		}
 
@@ -117,21 +117,21 @@ func TestNodeAddRemoveChild(t *testing.T) {
 		NoChildMap   bool
 		ExistingRune bool
 		RemoveChild  bool
-		Result       string
+		ExpAdded     bool
 	}{
 		{
-			Name:   "new rune is added successfully",
-			Result: nodeAdded,
+			Name:     "new rune is added successfully",
+			ExpAdded: true,
 		},
 		{
 			Name:       "new rune is added successfully when child map has not been initialized",
 			NoChildMap: true,
-			Result:     nodeAdded,
+			ExpAdded:   true,
 		},
 		{
 			Name:         "existing rune is found successfully",
 			ExistingRune: true,
-			Result:       nodeFound,
+			ExpAdded:     false,
 		},
 		{
 			Name:        "existing rune is removed succesfully",
@@ -175,21 +175,26 @@ func TestNodeAddRemoveChild(t *testing.T) {
 			}
 			exp.children['d'] = &node{}
 
-			n.RemoveChild('c')
+			n.removeChild('c')
 			assert.Equal(t, exp, n)
 			return
 		}
-		nResult := n.AddChild(ipRune)
+		wasTerm := n.isTerm
+		cNode, added := n.addChild(ipRune)
 
-		assert.Equal(t, test.Result, nResult.result)
+		assert.Equal(t, test.ExpAdded, added)
+		assert.Equal(t, n.children[cRune], cNode)
 		if _, ok := n.children[cRune]; !ok {
 			t.Fatalf("child for %c does not exist", cRune)
 		}
 		if n.children[cRune].Value() != cRune {
 			t.Fatalf("child for %c does not have correct value", cRune)
 		}
-		if n.isTerm != false {
-			t.Fatalf("isTerm is not false")
+		if n.isTerm != wasTerm {
+			t.Fatalf("isTerm of the parent changed")
+		}
+		if test.ExpAdded && cNode.IsTerminal() {
+			t.Fatalf("added child is terminating")
 		}
 	}
 }
@@ -322,3 +327,108 @@ func TestNodeEqual(t *testing.T) {
 		assert.Equal(t, test.IsEqual, op)
 	}
 }
+
+func TestNodeHandle(t *testing.T) {
+	tr := New("test")
+	for word, data := range map[string]interface{}{"abc": 1, "abd": 2, "ab": 3} {
+		_, err := tr.Add(word, data)
+		require.Empty(t, err)
+	}
+	n, err := tr.Find("ab")
+	require.Empty(t, err)
+
+	var cases = []struct {
+		Name string
+		Fun  string
+		Out  interface{}
+	}{
+		{
+			Name: "Path works correctly",
+			Fun:  "Path",
+			Out:  "ab",
+		},
+		{
+			Name: "Path of root is empty",
+			Fun:  "RootPath",
+			Out:  "",
+		},
+		{
+			Name: "Depth works correctly",
+			Fun:  "Depth",
+			Out:  2,
+		},
+		{
+			Name: "Depth of root is zero",
+			Fun:  "RootDepth",
+			Out:  0,
+		},
+		{
+			Name: "Data works correctly",
+			Fun:  "Data",
+			Out:  3,
+		},
+		{
+			Name: "IsTerminal works correctly",
+			Fun:  "IsTerminal",
+			Out:  true,
+		},
+		{
+			Name: "Child works correctly",
+			Fun:  "Child",
+			Out:  "abd",
+		},
+		{
+			Name: "missing Child is not found",
+			Fun:  "MissingChild",
+			Out:  false,
+		},
+		{
+			Name: "SortedChildren works correctly",
+			Fun:  "SortedChildren",
+			Out:  []string{"abc", "abd"},
+		},
+		{
+			Name: "changing Children does not change the node",
+			Fun:  "Children",
+			Out:  []string{"abc", "abd"},
+		},
+	}
+
+	for _, test := range cases {
+		var op interface{}
+
+		if test.Fun == "Path" {
+			op = n.Path()
+		} else if test.Fun == "RootPath" {
+			op = tr.Root.Path()
+		} else if test.Fun == "Depth" {
+			op = n.Depth()
+		} else if test.Fun == "RootDepth" {
+			op = tr.Root.Depth()
+		} else if test.Fun == "Data" {
+			op = n.Data()
+		} else if test.Fun == "IsTerminal" {
+			op = n.IsTerminal()
+		} else if test.Fun == "Child" {
+			cNode, ok := n.Child('d')
+			require.True(t, ok, test.Name)
+			op = cNode.Path()
+		} else if test.Fun == "MissingChild" {
+			_, op = n.Child('x')
+		} else if test.Fun == "SortedChildren" {
+			paths := []string{}
+			for _, cNode := range n.SortedChildren() {
+				paths = append(paths, cNode.Path())
+			}
+			op = paths
+		} else if test.Fun == "Children" {
+			delete(n.Children(), 'c')
+			op = tr.WordsWithPrefix("abc")
+			op = append(op.([]string), tr.WordsWithPrefix("abd")...)
+		}
+
+		if !cmp.Equal(test.Out, op) {
+			t.Fatalf("%s: expected and actual do not match: %s", test.Name, cmp.Diff(test.Out, op))
+		}
+	}
+}
@@ -12,17 +12,17 @@ import (
 
 func TestNewFromFile(t *testing.T) {
 	expTr := New("test")
-	expTr.Root.Children()['a'] = &node{
+	expTr.Root.(*node).children['a'] = &node{
 		value:    'a',
 		parent:   expTr.Root,
 		children: make(childNodeMap),
 	}
-	expTr.Root.Children()['a'].Children()['b'] = &node{
+	expTr.Root.(*node).children['a'].(*node).children['b'] = &node{
 		value:  'b',
-		parent: expTr.Root.Children()['a'],
+		parent: expTr.Root.(*node).children['a'],
 		isTerm: true,
 	}
-	expTr.Root.Children()['b'] = &node{
+	expTr.Root.(*node).children['b'] = &node{
 		value:  'b',
 		parent: expTr.Root,
 		isTerm: true,
@@ -76,17 +76,17 @@ func TestNewFromFile(t *testing.T) {
 
 func TestFind(t *testing.T) {
 	tr := New("")
-	tr.Root.Children()['a'] = &node{
+	tr.Root.(*node).children['a'] = &node{
 		value:    'a',
 		parent:   tr.Root,
 		children: make(childNodeMap),
 	}
-	tr.Root.Children()['a'].Children()['b'] = &node{
+	tr.Root.(*node).children['a'].(*node).children['b'] = &node{
 		value:  'b',
-		parent: tr.Root.Children()['a'],
+		parent: tr.Root.(*node).children['a'],
 		isTerm: true,
 	}
-	tr.Root.Children()['b'] = &node{
+	tr.Root.(*node).children['b'] = &node{
 		value:  'b',
 		parent: tr.Root,
 		isTerm: true,
@@ -165,7 +165,7 @@ func TestAdd(t *testing.T) {
 		{
 			Name:      "word in trie throws error",
 			Input:     "in-trie",
-			ExpectErr: "word already exists in trie",
+			ExpectErr: "word ab already exists in trie",
 		},
 		{
 			Name:  "word in trie but not as terminating is added successfully",
@@ -226,23 +226,23 @@ func TestRemove(t *testing.T) {
 	for _, test := range cases {
 		var err error
 		tr := New("test")
-		tr.Root.Children()['a'] = &node{
+		tr.Root.(*node).children['a'] = &node{
 			value:    'a',
 			parent:   tr.Root,
 			children: make(childNodeMap),
 		}
-		tr.Root.Children()['a'].Children()['b'] = &node{
+		tr.Root.(*node).children['a'].(*node).children['b'] = &node{
 			value:    'b',
-			parent:   tr.Root.Children()['a'],
+			parent:   tr.Root.(*node).children['a'],
 			isTerm:   true,
 			children: make(childNodeMap),
 		}
-		tr.Root.Children()['a'].Children()['b'].Children()['c'] = &node{
+		tr.Root.(*node).children['a'].(*node).children['b'].(*node).children['c'] = &node{
 			value:  'c',
-			parent: tr.Root.Children()['a'].Children()['b'],
+			parent: tr.Root.(*node).children['a'].(*node).children['b'],
 			isTerm: true,
 		}
-		tr.Root.Children()['b'] = &node{
+		tr.Root.(*node).children['b'] = &node{
 			value:  'b',
 			parent: tr.Root,
 			isTerm: true,
@@ -280,17 +280,17 @@ func TestWords(t *testing.T) {
 
 	for _, test := range cases {
 		tr := New("test")
-		tr.Root.Children()['a'] = &node{
+		tr.Root.(*node).children['a'] = &node{
 			value:    'a',
 			parent:   tr.Root,
 			children: make(childNodeMap),
 		}
-		tr.Root.Children()['a'].Children()['b'] = &node{
+		tr.Root.(*node).children['a'].(*node).children['b'] = &node{
 			value:  'b',
-			parent: tr.Root.Children()['a'],
+			parent: tr.Root.(*node).children['a'],
 			isTerm: true,
 		}
-		tr.Root.Children()['b'] = &node{
+		tr.Root.(*node).children['b'] = &node{
 			value:  'b',
 			parent: tr.Root,
 			isTerm: true,
@@ -320,19 +320,19 @@ func TestTree(t *testing.T) {
 	for _, test := range cases {
 		trieName := "test"
 		tr := New(trieName)
-		tr.Root.Children()['a'] = &node{
+		tr.Root.(*node).children['a'] = &node{
 			value:      'a',
 			parent:     tr.Root,
 			children:   make(childNodeMap),
 			childCount: 1,
 		}
-		tr.Root.Children()['a'].Children()['b'] = &node{
+		tr.Root.(*node).children['a'].(*node).children['b'] = &node{
 			value:      'b',
-			parent:     tr.Root.Children()['a'],
+			parent:     tr.Root.(*node).children['a'],
 			childCount: 1,
 			isTerm:     true,
 		}
-		tr.Root.Children()['b'] = &node{
+		tr.Root.(*node).children['b'] = &node{
 			value:      'b',
 			parent:     tr.Root,
 			childCount: 1,
@@ -353,3 +353,274 @@ func TestTree(t *testing.T) {
 		assert.Equal(t, expTree, tree, test.Name)
 	}
 }
+
+func TestAddKeepsPrefixWords(t *testing.T) {
+	var cases = []struct {
+		Name  string
+		Words []string
+	}{
+		{
+			Name:  "shorter word added first stays a word",
+			Words: []string{"ab", "abc"},
+		},
+		{
+			Name:  "shorter word added last becomes a word",
+			Words: []string{"abc", "ab"},
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test")
+		for _, word := range test.Words {
+			_, err := tr.Add(word, "")
+			require.Empty(t, err, test.Name)
+		}
+
+		for _, word := range test.Words {
+			_, err := tr.Find(word)
+			assert.Empty(t, err, test.Name+": "+word)
+		}
+		assert.ElementsMatch(t, test.Words, tr.Words(), test.Name)
+	}
+}
+
+func TestWordsWithPrefix(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Prefix   string
+		ExpWords []string
+	}{
+		{
+			Name:     "words with prefix are returned",
+			Prefix:   "car",
+			ExpWords: []string{"car", "cart", "carton"},
+		},
+		{
+			Name:     "prefix that is not a word still matches",
+			Prefix:   "ca",
+			ExpWords: []string{"car", "cart", "carton", "cat"},
+		},
+		{
+			Name:     "missing prefix returns no words",
+			Prefix:   "dog",
+			ExpWords: []string{},
+		},
+		{
+			Name:     "empty prefix returns all words",
+			Prefix:   "",
+			ExpWords: []string{"car", "cart", "carton", "cat", "bat"},
+		},
+	}
+
+	tr := New("test")
+	for _, word := range []string{"car", "cart", "carton", "cat", "bat"} {
+		_, err := tr.Add(word, "")
+		require.Empty(t, err)
+	}
+
+	for _, test := range cases {
+		words := tr.WordsWithPrefix(test.Prefix)
+		assert.ElementsMatch(t, test.ExpWords, words, test.Name)
+	}
+}
+
+func TestLongestPrefixOf(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Input     string
+		ExpWord   string
+		ExpectErr string
+	}{
+		{
+			Name:    "longest word prefix is returned",
+			Input:   "cartons",
+			ExpWord: "carton",
+		},
+		{
+			Name:    "non terminating path is skipped",
+			Input:   "carto",
+			ExpWord: "cart",
+		},
+		{
+			Name:    "exact word is its own longest prefix",
+			Input:   "car",
+			ExpWord: "car",
+		},
+		{
+			Name:      "no prefix throws error",
+			Input:     "ca",
+			ExpectErr: "no prefix of ca found",
+		},
+	}
+
+	tr := New("test")
+	for _, word := range []string{"car", "cart", "carton"} {
+		_, err := tr.Add(word, "")
+		require.Empty(t, err)
+	}
+
+	for _, test := range cases {
+		word, err := tr.LongestPrefixOf(test.Input)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.Equal(t, test.ExpWord, word, test.Name)
+	}
+}
+
+func TestWordsWithSuffix(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Suffix    string
+		NoIndex   bool
+		Remove    string
+		ExpWords  []string
+		ExpectErr string
+	}{
+		{
+			Name:     "words with suffix are returned",
+			Suffix:   "ing",
+			ExpWords: []string{"sing", "singing", "ring"},
+		},
+		{
+			Name:     "removed words are not returned",
+			Suffix:   "ing",
+			Remove:   "singing",
+			ExpWords: []string{"sing", "ring"},
+		},
+		{
+			Name:     "missing suffix returns no words",
+			Suffix:   "ed",
+			ExpWords: []string{},
+		},
+		{
+			Name:      "trie without suffix index throws error",
+			Suffix:    "ing",
+			NoIndex:   true,
+			ExpectErr: "suffix index is not enabled",
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test", WithSuffixIndex())
+		if test.NoIndex {
+			tr = New("test")
+		}
+		for _, word := range []string{"sing", "singing", "ring", "sings"} {
+			_, err := tr.Add(word, "")
+			require.Empty(t, err, test.Name)
+		}
+		if test.Remove != "" {
+			require.Empty(t, tr.Remove(test.Remove), test.Name)
+		}
+
+		words, err := tr.WordsWithSuffix(test.Suffix)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, words, test.Name)
+	}
+}
+
+func TestSuffixIndexFailure(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Fun       string
+		ExpWords  []string
+		ExpectErr string
+	}{
+		{
+			Name:      "add is reverted when the suffix index already has the word",
+			Fun:       "Add",
+			ExpWords:  []string{"sing"},
+			ExpectErr: "could not add word singer to suffix index",
+		},
+		{
+			Name:      "update is reverted when the suffix index already has the word",
+			Fun:       "Update",
+			ExpWords:  []string{"sing"},
+			ExpectErr: "could not add word singer to suffix index",
+		},
+		{
+			Name:      "remove leaves the word when the suffix index does not have it",
+			Fun:       "Remove",
+			ExpWords:  []string{"sing"},
+			ExpectErr: "could not remove word sing from suffix index",
+		},
+	}
+
+	for _, test := range cases {
+		tr := New("test", WithSuffixIndex())
+		_, err := tr.Add("sing", "")
+		require.Empty(t, err, test.Name)
+
+		if test.Fun == "Add" {
+			_, err = tr.suffix.Add(reverse("singer"), nil)
+			require.Empty(t, err, test.Name)
+			_, err = tr.Add("singer", "")
+		} else if test.Fun == "Update" {
+			_, err = tr.suffix.Add(reverse("singer"), nil)
+			require.Empty(t, err, test.Name)
+			_, _, err = tr.Put("singer", "")
+		} else if test.Fun == "Remove" {
+			require.Empty(t, tr.suffix.Remove(reverse("sing")), test.Name)
+			err = tr.Remove("sing")
+		}
+
+		require.NotEmpty(t, err, test.Name)
+		assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		assert.Equal(t, 5, tr.Stats().Nodes, test.Name)
+	}
+}
+
+func TestLongestSuffixOf(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		Input     string
+		ExpWord   string
+		ExpectErr string
+	}{
+		{
+			Name:    "longest domain suffix is returned",
+			Input:   "www.example.com",
+			ExpWord: "example.com",
+		},
+		{
+			Name:    "shorter suffix is returned when longer does not match",
+			Input:   "www.other.com",
+			ExpWord: ".com",
+		},
+		{
+			Name:      "no suffix throws error",
+			Input:     "example.org",
+			ExpectErr: "no suffix of example.org found",
+		},
+	}
+
+	tr := New("test", WithSuffixIndex())
+	for _, word := range []string{".com", "example.com", "mail.example.com"} {
+		_, err := tr.Add(word, "")
+		require.Empty(t, err)
+	}
+
+	for _, test := range cases {
+		word, err := tr.LongestSuffixOf(test.Input)
+
+		if test.ExpectErr != "" {
+			require.NotEmpty(t, err, test.Name)
+			assert.Contains(t, err.Error(), test.ExpectErr, test.Name)
+			continue
+		}
+		assert.Empty(t, err, test.Name)
+		assert.Equal(t, test.ExpWord, word, test.Name)
+	}
+}
@@ -0,0 +1,249 @@
+package trie
+
+import (
+	"container/heap"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ExpireFunc is called with every word removed because its time to live has passed
+type ExpireFunc func(word string, data interface{})
+
+// expiry holds the clock, callback and deadlines of the words added with a time to live
+type expiry struct {
+	now       func() time.Time
+	onExpire  ExpireFunc
+	deadlines map[string]time.Time
+	queue     expiryQueue
+}
+
+// expiryItem is a deadline of a key in the queue. Items whose deadline no longer matches the
+// deadline of their key are stale and skipped
+type expiryItem struct {
+	key      string
+	deadline time.Time
+}
+
+// expiryQueue is a min-heap of deadlines
+type expiryQueue []expiryItem
+
+func (q expiryQueue) Len() int            { return len(q) }
+func (q expiryQueue) Less(i, j int) bool  { return q[i].deadline.Before(q[j].deadline) }
+func (q expiryQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *expiryQueue) Push(x interface{}) { *q = append(*q, x.(expiryItem)) }
+func (q *expiryQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// WithClock sets the clock used for the deadlines of words added with a time to live, time.Now by
+// default
+func WithClock(now func() time.Time) Option {
+	return func(t *Trie) {
+		t.expiry().now = now
+	}
+}
+
+// WithExpiration calls fn with every word removed because its time to live has passed. Words removed
+// during a batch are only passed to fn once the batch succeeds
+func WithExpiration(fn ExpireFunc) Option {
+	return func(t *Trie) {
+		t.expiry().onExpire = fn
+	}
+}
+
+// expiry returns the expiration state of the trie, enabling it on first use
+func (t *Trie) expiry() *expiry {
+	if t.ttl == nil {
+		t.ttl = &expiry{
+			now:       time.Now,
+			deadlines: make(map[string]time.Time),
+		}
+	}
+	return t.ttl
+}
+
+// AddWithTTL adds the word with its data like Add and removes it once ttl has passed. Until the
+// janitor or RemoveExpired removes it, queries no longer see the word and changes treat it as absent,
+// removing it first. Replacing the data of the word keeps its deadline, which SetTTL changes
+func (t *Trie) AddWithTTL(word string, data interface{}, ttl time.Duration) (Node, error) {
+	termNode, err := t.add(word, data)
+	if err != nil {
+		return nil, err
+	}
+
+	e := t.expiry()
+	t.setDeadline(t.key(word), e.now().Add(ttl))
+
+	return expose(termNode), nil
+}
+
+// TTL gives the time the word has left to live, or false if it was not added with a time to live
+func (t *Trie) TTL(word string) (time.Duration, bool) {
+	if t.ttl == nil {
+		return 0, false
+	}
+
+	deadline, ok := t.ttl.deadlines[t.key(word)]
+	if !ok {
+		return 0, false
+	}
+	return deadline.Sub(t.ttl.now()), true
+}
+
+// SetTTL gives the word ttl left to live from now, or lets it live until it is removed if ttl is not
+// positive. An error is returned if the word is not in the trie
+func (t *Trie) SetTTL(word string, ttl time.Duration) error {
+	key := t.key(word)
+	t.reap(key)
+	if _, err := t.find(word); err != nil {
+		return fmt.Errorf("could not find word %s in trie: %w", word, err)
+	}
+
+	if ttl <= 0 {
+		t.setDeadline(key, time.Time{})
+		return nil
+	}
+	t.setDeadline(key, t.expiry().now().Add(ttl))
+
+	return nil
+}
+
+// RemoveExpired removes the words whose time to live has passed, prunes their branches like Remove
+// and returns how many were removed
+func (t *Trie) RemoveExpired() int {
+	if t.ttl == nil {
+		return 0
+	}
+
+	now := t.ttl.now()
+	removed := 0
+	for t.ttl.queue.Len() > 0 && !t.ttl.queue[0].deadline.After(now) {
+		item := heap.Pop(&t.ttl.queue).(expiryItem)
+		if deadline, ok := t.ttl.deadlines[item.key]; ok && deadline.Equal(item.deadline) {
+			t.expire(item.key)
+			removed++
+		}
+	}
+
+	return removed
+}
+
+// expired tells if the key has a deadline which has passed
+func (t *Trie) expired(key string) bool {
+	if t.ttl == nil {
+		return false
+	}
+
+	deadline, ok := t.ttl.deadlines[key]
+	return ok && !deadline.After(t.ttl.now())
+}
+
+// expiredNode is the node of a word whose time to live has passed as Walk hands it out, no longer
+// terminating a word
+type expiredNode struct {
+	Node
+}
+
+func (n expiredNode) IsTerminal() bool  { return false }
+func (n expiredNode) Data() interface{} { return nil }
+
+// expiredPath tells if the word whose key is made of the runes has a deadline which has passed
+func (t *Trie) expiredPath(runes []rune) bool {
+	return t.ttl != nil && t.expired(string(runes))
+}
+
+// reap removes the word of the key if its time to live has passed, so that changing the word treats
+// it as absent
+func (t *Trie) reap(key string) {
+	if t.expired(key) {
+		t.expire(key)
+	}
+}
+
+// expire removes the word of the key and calls the expiration callback
+func (t *Trie) expire(key string) {
+	// The deadline is cleared first so that Remove does not find the word expired
+	t.setDeadline(key, time.Time{})
+
+	n := t.nodeAtPrefix([]rune(key))
+	if n == nil || !n.IsTerminal() {
+		return
+	}
+
+	word, data := t.spelling(key), n.Data()
+	if err := t.Remove(word); err != nil {
+		return
+	}
+	if onExpire := t.ttl.onExpire; onExpire != nil {
+		t.afterCommit(func() { onExpire(word, data) })
+	}
+}
+
+// setDeadline sets the deadline of the key, or clears it if deadline is zero, recording the previous
+// deadline in a batch
+func (t *Trie) setDeadline(key string, deadline time.Time) {
+	if t.ttl == nil {
+		return
+	}
+
+	old, ok := t.ttl.deadlines[key]
+	if !ok && deadline.IsZero() {
+		return
+	}
+	t.undo.record(func() {
+		if ok {
+			t.ttl.deadlines[key] = old
+		} else {
+			delete(t.ttl.deadlines, key)
+		}
+	})
+
+	if deadline.IsZero() {
+		delete(t.ttl.deadlines, key)
+		return
+	}
+	t.ttl.deadlines[key] = deadline
+	heap.Push(&t.ttl.queue, expiryItem{key: key, deadline: deadline})
+}
+
+// StartJanitor removes the expired words of the trie every interval in a goroutine until the
+// returned function is called. The janitor holds lock while it removes words, so lock must be the
+// lock guarding every other use of the trie, or nil if there is none
+func (t *Trie) StartJanitor(interval time.Duration, lock sync.Locker) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if lock != nil {
+					lock.Lock()
+				}
+				t.RemoveExpired()
+				if lock != nil {
+					lock.Unlock()
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,487 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a clock which only moves when told to
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRemoveExpired(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+	expired := map[string]interface{}{}
+	tr := New("ttl", WithClock(clock.Now), WithExpiration(func(word string, data interface{}) {
+		expired[word] = data
+	}), WithCaseFolding())
+
+	_, err := tr.Add("car", 0)
+	require.NoError(t, err)
+	_, err = tr.AddWithTTL("Card", 1, time.Minute)
+	require.NoError(t, err)
+	_, err = tr.AddWithTTL("cart", 2, time.Hour)
+	require.NoError(t, err)
+	_, err = tr.AddWithTTL("dog", 3, 2*time.Minute)
+	require.NoError(t, err)
+	_, err = tr.AddWithTTL("dog", 4, time.Hour)
+	assert.ErrorIs(t, err, ErrExists)
+
+	left, ok := tr.TTL("card")
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, left)
+	_, ok = tr.TTL("car")
+	assert.False(t, ok)
+
+	var cases = []struct {
+		Name       string
+		Advance    time.Duration
+		ExpRemoved int
+		ExpWords   []string
+		ExpExpired map[string]interface{}
+	}{
+		{
+			Name:       "nothing expires before the deadline",
+			Advance:    59 * time.Second,
+			ExpWords:   []string{"car", "Card", "cart", "dog"},
+			ExpExpired: map[string]interface{}{},
+		},
+		{
+			Name:       "word expires at the deadline",
+			Advance:    time.Second,
+			ExpRemoved: 1,
+			ExpWords:   []string{"car", "cart", "dog"},
+			ExpExpired: map[string]interface{}{"Card": 1},
+		},
+		{
+			Name:       "branch of expired word is pruned",
+			Advance:    time.Hour,
+			ExpRemoved: 2,
+			ExpWords:   []string{"car"},
+			ExpExpired: map[string]interface{}{"Card": 1, "cart": 2, "dog": 3},
+		},
+	}
+
+	for _, test := range cases {
+		clock.Advance(test.Advance)
+		assert.Equal(t, test.ExpRemoved, tr.RemoveExpired(), test.Name)
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		assert.Equal(t, test.ExpExpired, expired, test.Name)
+	}
+
+	_, ok = tr.Root.Child('d')
+	assert.False(t, ok)
+	assert.Empty(t, tr.ttl.deadlines)
+}
+
+func TestExpiredWordIsNotFound(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+	var expired []string
+	tr := New("ttl", WithClock(clock.Now), WithExpiration(func(word string, _ interface{}) {
+		expired = append(expired, word)
+	}))
+
+	_, err := tr.AddWithTTL("token", "session", time.Minute)
+	require.NoError(t, err)
+	clock.Advance(time.Minute)
+
+	// Finding the word does not remove it, which is left to RemoveExpired
+	_, err = tr.Find("token")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Empty(t, expired)
+	assert.Equal(t, 1, tr.RemoveExpired())
+	assert.Equal(t, []string{"token"}, expired)
+
+	// Removing a word clears its deadline, so the word added again lives
+	_, err = tr.AddWithTTL("token", "old", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, tr.Remove("token"))
+	_, err = tr.Add("token", "new")
+	require.NoError(t, err)
+	clock.Advance(time.Hour)
+	assert.Zero(t, tr.RemoveExpired())
+	_, err = tr.Find("token")
+	assert.NoError(t, err)
+}
+
+func TestExpiredWordIsHidden(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Fun      string
+		Input    string
+		ExpWords []string
+	}{
+		{
+			Name:     "words skip expired word",
+			Fun:      "Words",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "words with prefix skip expired word",
+			Fun:      "WordsWithPrefix",
+			Input:    "ca",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "words with suffix skip expired word",
+			Fun:      "WordsWithSuffix",
+			Input:    "t",
+			ExpWords: []string{"cart"},
+		},
+		{
+			Name:     "longest prefix falls back to a live word",
+			Fun:      "LongestPrefixOf",
+			Input:    "cards",
+			ExpWords: []string{"car"},
+		},
+		{
+			Name:     "longest suffix falls back to a live word",
+			Fun:      "LongestSuffixOf",
+			Input:    "scart",
+			ExpWords: []string{"cart"},
+		},
+		{
+			Name:     "fuzzy find skips expired word",
+			Fun:      "FuzzyFind",
+			Input:    "car",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "walk hands out expired word as non-terminating",
+			Fun:      "Walk",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "save skips expired word",
+			Fun:      "Save",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "freeze skips expired word",
+			Fun:      "Freeze",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "diff skips expired word",
+			Fun:      "Diff",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "union skips expired word",
+			Fun:      "Union",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "intersection skips expired word",
+			Fun:      "Intersect",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "difference skips expired word",
+			Fun:      "Difference",
+			ExpWords: []string{"car", "cart"},
+		},
+		{
+			Name:     "difference keeps word expired in the other trie",
+			Fun:      "DifferenceOf",
+			ExpWords: []string{"card", "scart"},
+		},
+	}
+
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+	tr := New("ttl", WithClock(clock.Now), WithSuffixIndex())
+	for _, word := range []string{"car", "cart"} {
+		_, err := tr.Add(word, nil)
+		require.NoError(t, err)
+	}
+	for _, word := range []string{"card", "scart"} {
+		_, err := tr.AddWithTTL(word, nil, time.Minute)
+		require.NoError(t, err)
+	}
+	clock.Advance(time.Minute)
+	all := New("all")
+	for _, word := range []string{"car", "cart", "card", "scart"} {
+		_, err := all.Add(word, nil)
+		require.NoError(t, err)
+	}
+
+	for _, test := range cases {
+		var words []string
+		var err error
+
+		if test.Fun == "Words" {
+			words = tr.Words()
+		} else if test.Fun == "WordsWithPrefix" {
+			words = tr.WordsWithPrefix(test.Input)
+		} else if test.Fun == "WordsWithSuffix" {
+			words, err = tr.WordsWithSuffix(test.Input)
+		} else if test.Fun == "LongestPrefixOf" {
+			var word string
+			word, err = tr.LongestPrefixOf(test.Input)
+			words = []string{word}
+		} else if test.Fun == "LongestSuffixOf" {
+			var word string
+			word, err = tr.LongestSuffixOf(test.Input)
+			words = []string{word}
+		} else if test.Fun == "FuzzyFind" {
+			for _, match := range tr.FuzzyFind(test.Input, 1) {
+				words = append(words, match.Word)
+			}
+		} else if test.Fun == "Walk" {
+			tr.Walk(func(path []rune, n Node) WalkAction {
+				if n.IsTerminal() {
+					words = append(words, string(path))
+				}
+				return Continue
+			})
+		} else if test.Fun == "Save" {
+			var buf bytes.Buffer
+			require.NoError(t, tr.Save(&buf), test.Name)
+			var loaded *Trie
+			loaded, err = Load(&buf)
+			if err == nil {
+				words = loaded.Words()
+			}
+		} else if test.Fun == "Freeze" {
+			words = tr.Freeze().Words()
+		} else if test.Fun == "Diff" {
+			for _, change := range Diff(New("empty"), tr) {
+				words = append(words, change.Key)
+			}
+		} else {
+			var res *Trie
+			switch test.Fun {
+			case "Union":
+				res, err = Union(tr, New("empty"), nil)
+			case "Intersect":
+				res, err = Intersect(tr, all, nil)
+			case "Difference":
+				res, err = Difference(tr, New("empty"))
+			case "DifferenceOf":
+				res, err = Difference(all, tr)
+			}
+			if err == nil {
+				words = res.Words()
+			}
+		}
+
+		require.NoError(t, err, test.Name)
+		assert.ElementsMatch(t, test.ExpWords, words, test.Name)
+	}
+	assert.Equal(t, 2, tr.RemoveExpired())
+}
+
+func TestExpiredWordIsNotCounted(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+	tr := New("ttl", WithClock(clock.Now))
+	_, err := tr.Add("car", 1)
+	require.NoError(t, err)
+	_, err = tr.AddWithTTL("cards", 2, time.Minute)
+	require.NoError(t, err)
+	clock.Advance(time.Minute)
+
+	stats := tr.Stats()
+	assert.Equal(t, 1, stats.Words)
+	assert.Equal(t, 3.0, stats.AvgDepth)
+
+	var buf bytes.Buffer
+	require.NoError(t, tr.WriteDOT(&buf, ExportOptions{HighlightTerminal: true, ShowData: true}))
+	assert.Equal(t, 1, strings.Count(buf.String(), "doublecircle"))
+	assert.NotContains(t, buf.String(), "s: 2")
+}
+
+func TestExpiredWordIsAbsentToChanges(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Fun        string
+		ExpErr     error
+		ExpExpired []string
+	}{
+		{
+			Name:       "adding expired word adds it again",
+			Fun:        "Add",
+			ExpExpired: []string{"token"},
+		},
+		{
+			Name:       "removing expired word does not find it",
+			Fun:        "Remove",
+			ExpErr:     ErrNotFound,
+			ExpExpired: []string{"token"},
+		},
+		{
+			Name:       "setting time to live of expired word does not find it",
+			Fun:        "SetTTL",
+			ExpErr:     ErrNotFound,
+			ExpExpired: []string{"token"},
+		},
+	}
+
+	for _, test := range cases {
+		clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+		var expired []string
+		tr := New("ttl", WithClock(clock.Now), WithExpiration(func(word string, _ interface{}) {
+			expired = append(expired, word)
+		}))
+		_, err := tr.AddWithTTL("token", "old", time.Minute)
+		require.NoError(t, err, test.Name)
+		clock.Advance(time.Minute)
+
+		if test.Fun == "Add" {
+			_, err = tr.Add("token", "new")
+		} else if test.Fun == "Remove" {
+			err = tr.Remove("token")
+		} else if test.Fun == "SetTTL" {
+			err = tr.SetTTL("token", time.Hour)
+		}
+
+		if test.ExpErr != nil {
+			assert.ErrorIs(t, err, test.ExpErr, test.Name)
+		} else {
+			assert.NoError(t, err, test.Name)
+		}
+		assert.Equal(t, test.ExpExpired, expired, test.Name)
+		assert.Zero(t, tr.RemoveExpired(), test.Name)
+	}
+}
+
+func TestExpirationInBatch(t *testing.T) {
+	var cases = []struct {
+		Name       string
+		Fail       bool
+		ExpData    interface{}
+		ExpExpired []string
+	}{
+		{
+			Name:    "failed batch restores expired word without calling back",
+			Fail:    true,
+			ExpData: "old",
+		},
+		{
+			Name:       "successful batch calls back once committed",
+			ExpData:    "new",
+			ExpExpired: []string{"token"},
+		},
+	}
+
+	for _, test := range cases {
+		clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+		var expired []string
+		tr := New("ttl", WithClock(clock.Now), WithExpiration(func(word string, _ interface{}) {
+			expired = append(expired, word)
+		}))
+		_, err := tr.AddWithTTL("token", "old", time.Minute)
+		require.NoError(t, err, test.Name)
+		clock.Advance(time.Minute)
+
+		err = tr.Batch(func(tx *Tx) error {
+			if _, _, err := tx.Put("token", "new"); err != nil {
+				return err
+			}
+			assert.Empty(t, expired, test.Name)
+			if test.Fail {
+				return errors.New("bad line")
+			}
+			return nil
+		})
+		assert.Equal(t, test.Fail, err != nil, test.Name)
+		assert.Equal(t, test.ExpExpired, expired, test.Name)
+
+		// The failed batch leaves the word expired, so it is only seen through its node
+		n := tr.nodeAtPrefix([]rune("token"))
+		require.NotNil(t, n, test.Name)
+		assert.Equal(t, test.ExpData, n.Data(), test.Name)
+	}
+}
+
+func TestSetTTL(t *testing.T) {
+	var cases = []struct {
+		Name      string
+		TTL       time.Duration
+		ExpWords  []string
+		ExpHasTTL bool
+	}{
+		{
+			Name:      "refreshed word outlives its old deadline",
+			TTL:       time.Hour,
+			ExpWords:  []string{"token"},
+			ExpHasTTL: true,
+		},
+		{
+			Name:     "word without time to live is kept",
+			TTL:      0,
+			ExpWords: []string{"token"},
+		},
+		{
+			Name:     "shortened word expires",
+			TTL:      time.Second,
+			ExpWords: []string{},
+		},
+	}
+
+	for _, test := range cases {
+		clock := &fakeClock{now: time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)}
+		tr := New("ttl", WithClock(clock.Now))
+		_, err := tr.AddWithTTL("token", nil, 2*time.Minute)
+		require.NoError(t, err, test.Name)
+
+		require.NoError(t, tr.SetTTL("token", test.TTL), test.Name)
+		clock.Advance(2 * time.Minute)
+		tr.RemoveExpired()
+
+		assert.ElementsMatch(t, test.ExpWords, tr.Words(), test.Name)
+		_, ok := tr.TTL("token")
+		assert.Equal(t, test.ExpHasTTL, ok, test.Name)
+	}
+
+	tr := New("ttl")
+	assert.ErrorIs(t, tr.SetTTL("missing", time.Minute), ErrNotFound)
+}
+
+func TestStartJanitor(t *testing.T) {
+	var mu sync.Mutex
+	removed := make(chan string, 1)
+	tr := New("ttl", WithExpiration(func(word string, _ interface{}) {
+		removed <- word
+	}))
+
+	mu.Lock()
+	_, err := tr.AddWithTTL("token", nil, time.Millisecond)
+	mu.Unlock()
+	require.NoError(t, err)
+
+	stop := tr.StartJanitor(time.Millisecond, &mu)
+	defer stop()
+
+	select {
+	case word := <-removed:
+		assert.Equal(t, "token", word)
+	case <-time.After(5 * time.Second):
+		t.Fatal("janitor did not remove the expired word")
+	}
+
+	stop()
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Empty(t, tr.Words())
+}
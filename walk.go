@@ -0,0 +1,92 @@
+package trie
+
+// WalkAction tells Walk how to continue after visiting a node
+type WalkAction int
+
+const (
+	// Continue visits the children of the node and then the rest of the trie
+	Continue WalkAction = iota
+	// SkipChildren does not visit the children of the node. It has no effect in post-order walks,
+	// where the children have already been visited
+	SkipChildren
+	// Stop ends the walk
+	Stop
+)
+
+// WalkFunc is called for every node visited by Walk with the runes leading to the node. The path
+// is reused between calls so it must be copied to be kept
+type WalkFunc func(path []rune, n Node) WalkAction
+
+// walkConfig holds the options of a walk
+type walkConfig struct {
+	postOrder bool
+	sorted    bool
+	children  childStack
+}
+
+// WalkOption configures a walk
+type WalkOption func(*walkConfig)
+
+// PostOrder visits the children of a node before the node itself. By default nodes are visited in
+// pre-order
+func PostOrder() WalkOption {
+	return func(c *walkConfig) {
+		c.postOrder = true
+	}
+}
+
+// SortedChildren visits the children of a node in ascending rune order. By default the order of
+// children is unspecified
+func SortedChildren() WalkOption {
+	return func(c *walkConfig) {
+		c.sorted = true
+	}
+}
+
+// Walk visits every node of the trie beginning with the root, whose path is empty, and calls fn for
+// each of them. The node of a word whose time to live has passed is handed out as non-terminating
+func (t *Trie) Walk(fn WalkFunc, opts ...WalkOption) {
+	t.walk(func(path []rune, n Node) WalkAction {
+		if n.IsTerminal() && t.expiredPath(path) {
+			return fn(path, expiredNode{expose(n)})
+		}
+		return fn(path, expose(n))
+	}, opts...)
+}
+
+// walk visits the nodes of the trie like Walk without handing them out
+func (t *Trie) walk(fn WalkFunc, opts ...WalkOption) {
+	cfg := &walkConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	walkAtNode(t.root(), []rune{}, cfg, fn)
+}
+
+// walkAtNode visits the node and the nodes below it. It returns false once the walk is stopped
+func walkAtNode(n Node, path []rune, cfg *walkConfig, fn WalkFunc) bool {
+	if !cfg.postOrder {
+		switch fn(path, n) {
+		case Stop:
+			return false
+		case SkipChildren:
+			return true
+		}
+	}
+
+	start, end := cfg.children.push(n, cfg.sorted)
+	for i := start; i < end; i++ {
+		cNode := cfg.children[i]
+		if !walkAtNode(cNode, append(path, cNode.Value()), cfg, fn) {
+			return false
+		}
+	}
+	cfg.children.pop(start)
+
+	if cfg.postOrder {
+		return fn(path, n) != Stop
+	}
+
+	return true
+}
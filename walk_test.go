@@ -0,0 +1,78 @@
+package trie
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalk(t *testing.T) {
+	var cases = []struct {
+		Name     string
+		Opts     []WalkOption
+		Skip     string
+		StopAt   string
+		ExpPaths []string
+	}{
+		{
+			Name:     "pre-order walk visits parents first",
+			Opts:     []WalkOption{SortedChildren()},
+			ExpPaths: []string{"", "a", "ab", "abc", "ad", "b"},
+		},
+		{
+			Name:     "post-order walk visits children first",
+			Opts:     []WalkOption{SortedChildren(), PostOrder()},
+			ExpPaths: []string{"abc", "ab", "ad", "a", "b", ""},
+		},
+		{
+			Name:     "skipped children are not visited",
+			Opts:     []WalkOption{SortedChildren()},
+			Skip:     "ab",
+			ExpPaths: []string{"", "a", "ab", "ad", "b"},
+		},
+		{
+			Name:     "stop ends the walk",
+			Opts:     []WalkOption{SortedChildren()},
+			StopAt:   "ad",
+			ExpPaths: []string{"", "a", "ab", "abc", "ad"},
+		},
+		{
+			Name:     "stop ends a post-order walk",
+			Opts:     []WalkOption{SortedChildren(), PostOrder()},
+			StopAt:   "ab",
+			ExpPaths: []string{"abc", "ab"},
+		},
+	}
+
+	tr := New("test")
+	for _, word := range []string{"abc", "ad", "b"} {
+		_, err := tr.Add(word, "")
+		require.Empty(t, err)
+	}
+
+	for _, test := range cases {
+		paths := []string{}
+		tr.Walk(func(path []rune, n Node) WalkAction {
+			paths = append(paths, string(path))
+			if test.Skip != "" && string(path) == test.Skip {
+				return SkipChildren
+			}
+			if test.StopAt != "" && string(path) == test.StopAt {
+				return Stop
+			}
+			return Continue
+		}, test.Opts...)
+
+		assert.Equal(t, test.ExpPaths, paths, test.Name)
+	}
+
+	unsorted := []string{}
+	tr.Walk(func(path []rune, n Node) WalkAction {
+		if n.IsTerminal() {
+			unsorted = append(unsorted, string(path))
+		}
+		return Continue
+	})
+	assert.ElementsMatch(t, tr.Words(), unsorted, "unsorted walk visits every word")
+}